@@ -0,0 +1,37 @@
+package trees
+
+// Aggregate describes a summary of the entries in a subtree, such as a sum, a maximum, or a count,
+// that avl.AVL and rbt.RBT can store in every node and keep current through rotations and fixups.
+// Combine must be associative and Identity must be its identity element:
+// Combine(Identity(), x) == Combine(x, Identity()) == x.
+// Combine does not need to be commutative; its arguments are always in key order.
+type Aggregate interface {
+	// Identity returns the aggregate of no entries.
+	Identity() interface{}
+	// Lift returns the aggregate of a single entry.
+	Lift(key, value interface{}) interface{}
+	// Combine returns the aggregate of two adjacent runs of entries, left before right.
+	Combine(left, right interface{}) interface{}
+}
+
+// AggregateFuncs adapts an identity element and a pair of functions to the Aggregate interface.
+type AggregateFuncs struct {
+	Zero        interface{}                               // the identity element
+	LiftFunc    func(key, value interface{}) interface{}  // the aggregate of a single entry
+	CombineFunc func(left, right interface{}) interface{} // an associative combining function
+}
+
+// Identity returns a.Zero.
+func (a AggregateFuncs) Identity() interface{} {
+	return a.Zero
+}
+
+// Lift calls a.LiftFunc.
+func (a AggregateFuncs) Lift(key, value interface{}) interface{} {
+	return a.LiftFunc(key, value)
+}
+
+// Combine calls a.CombineFunc.
+func (a AggregateFuncs) Combine(left, right interface{}) interface{} {
+	return a.CombineFunc(left, right)
+}
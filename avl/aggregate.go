@@ -0,0 +1,79 @@
+package avl
+
+import (
+	"github.com/chancetudor/trees"
+)
+
+// AggregateRange returns the aggregate of the entries whose keys lie between lo and hi, combined in key order.
+// Each bound may be inclusive, exclusive, or unbounded; see package trees.
+// The function visits at most two root-to-leaf paths, so it runs in O(log n).
+// It returns nil if the tree was not created with NewWithAggregate.
+func (tree *AVL) AggregateRange(lo, hi trees.Bound) interface{} {
+	if tree.aggregate == nil {
+		return nil
+	}
+	// descend to the highest node inside the range; the range lies entirely within its subtree
+	tempNode := tree.Root()
+	for tempNode != nil {
+		switch {
+		case !lo.LowerContains(tree.comparator, tempNode.key()):
+			tempNode = tempNode.rightChild()
+		case !hi.UpperContains(tree.comparator, tempNode.key()):
+			tempNode = tempNode.leftChild()
+		default:
+			return tree.aggregate.Combine(
+				tree.aggregate.Combine(tree.aggregateFrom(tempNode.leftChild(), lo), tree.lift(tempNode)),
+				tree.aggregateUpTo(tempNode.rightChild(), hi))
+		}
+	}
+
+	return tree.aggregate.Identity()
+}
+
+// aggregateFrom returns the aggregate of the entries in a subtree whose keys are within the lower bound lo.
+func (tree *AVL) aggregateFrom(node *Node, lo trees.Bound) interface{} {
+	result := tree.aggregate.Identity()
+	for node != nil {
+		if lo.LowerContains(tree.comparator, node.key()) {
+			// node and its right subtree are in range and precede everything gathered so far
+			result = tree.aggregate.Combine(
+				tree.aggregate.Combine(tree.lift(node), tree.subtreeAggregate(node.rightChild())), result)
+			node = node.leftChild()
+		} else {
+			node = node.rightChild()
+		}
+	}
+
+	return result
+}
+
+// aggregateUpTo returns the aggregate of the entries in a subtree whose keys are within the upper bound hi.
+func (tree *AVL) aggregateUpTo(node *Node, hi trees.Bound) interface{} {
+	result := tree.aggregate.Identity()
+	for node != nil {
+		if hi.UpperContains(tree.comparator, node.key()) {
+			// node and its left subtree are in range and follow everything gathered so far
+			result = tree.aggregate.Combine(
+				result, tree.aggregate.Combine(tree.subtreeAggregate(node.leftChild()), tree.lift(node)))
+			node = node.rightChild()
+		} else {
+			node = node.leftChild()
+		}
+	}
+
+	return result
+}
+
+// subtreeAggregate returns the aggregate stored in a node, or the identity if the node is nil.
+func (tree *AVL) subtreeAggregate(node *Node) interface{} {
+	if node == nil {
+		return tree.aggregate.Identity()
+	}
+
+	return node.agg
+}
+
+// lift returns the aggregate of a single node's entry.
+func (tree *AVL) lift(node *Node) interface{} {
+	return tree.aggregate.Lift(node.key(), node.value())
+}
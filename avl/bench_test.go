@@ -0,0 +1,85 @@
+package avl
+
+import (
+	"fmt"
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"testing"
+)
+
+// BenchmarkAVL_InsertDelete inserts and then deletes a random key in a tree of n keys,
+// so the tree keeps its size. With O(log n) height maintenance, the time per op grows
+// with log n: roughly by a constant step for each tenfold increase of n.
+func BenchmarkAVL_InsertDelete(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000, 1000000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			keys := make([]interface{}, n)
+			for i := range keys {
+				keys[i] = 2 * i
+			}
+			tree, err := FromSorted(utils.IntComparator, keys, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			r := rand.New(rand.NewSource(1))
+			inserts := make([]int, 4096)
+			for i := range inserts {
+				inserts[i] = 2*r.Intn(n) + 1
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				key := inserts[i%len(inserts)]
+				tree.Insert(key, nil)
+				tree.Delete(key)
+			}
+		})
+	}
+}
+
+// BenchmarkAVL_InsertSequential inserts ascending keys into a tree of n keys,
+// which rotates on every other insert.
+func BenchmarkAVL_InsertSequential(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000, 1000000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			keys := make([]interface{}, n)
+			for i := range keys {
+				keys[i] = i
+			}
+			tree, err := FromSorted(utils.IntComparator, keys, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				tree.Insert(n+i, nil)
+			}
+		})
+	}
+}
+
+// BenchmarkAVL_DeleteMin repeatedly deletes the smallest key of a tree of n keys, refilling it every n deletes.
+// Deleting from the left spine rotates nodes all the way up to the root, where each rotation
+// used to recompute the heights of whole subtrees.
+func BenchmarkAVL_DeleteMin(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000, 1000000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			keys := make([]interface{}, n)
+			for i := range keys {
+				keys[i] = i
+			}
+			var tree *AVL
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if i%n == 0 {
+					b.StopTimer()
+					tree, _ = FromSorted(utils.IntComparator, keys, nil)
+					b.StartTimer()
+				}
+				tree.Delete(i % n)
+			}
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package avl
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/chancetudor/trees"
+	"hash/crc32"
+)
+
+// The binary format written by MarshalBinary is
+//
+//	magic   4 bytes: "AVL" followed by a zero byte
+//	version byte: binaryVersion
+//	count   uvarint: the number of nodes
+//	nodes   every node in pre-order, each as
+//	        flags  byte: 1 if the node has a left child, plus 2 if it has a right child
+//	        height uvarint: the node's stored height
+//	        key    the key, encoded by the key Codec
+//	        value  the value, encoded by the value Codec
+//	crc     4 bytes: the little-endian CRC-32 (IEEE) of everything before it
+//
+// Pre-order with child flags is enough to rebuild the exact shape without comparing keys.
+// The heights could be recomputed from the shape; they are stored only for validation. UnmarshalBinary checks them
+// against the shape, and uses them to bound its recursion before reading a subtree, since the checksum
+// catches accidental corruption but not a crafted payload.
+const binaryVersion = 1
+
+// binaryMagic starts every encoded AVL.
+var binaryMagic = []byte("AVL\x00")
+
+// SetBinaryCodecs takes the Codecs that MarshalBinary and UnmarshalBinary use for keys and for values,
+// such as trees.IntCodec{} and trees.StringCodec{}.
+func (tree *AVL) SetBinaryCodecs(key, value trees.Codec) {
+	tree.keyCodec = key
+	tree.valueCodec = value
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// It encodes the exact shape of the tree in a versioned, checksummed format, keys and values included,
+// using the Codecs set by SetBinaryCodecs. The encoding takes O(n) time.
+// The function returns an error if no Codecs are set or a Codec cannot encode a key or value.
+func (tree *AVL) MarshalBinary() ([]byte, error) {
+	if tree.keyCodec == nil || tree.valueCodec == nil {
+		return nil, fmt.Errorf("ENCODING ERROR: no codecs; call SetBinaryCodecs first")
+	}
+	buf := append([]byte(nil), binaryMagic...)
+	buf = append(buf, binaryVersion)
+	buf = binary.AppendUvarint(buf, uint64(tree.Size()))
+	var err error
+	tree.walk(trees.PreOrder, func(node *Node) bool {
+		var flags byte
+		if node.leftChild() != nil {
+			flags |= 1
+		}
+		if node.rightChild() != nil {
+			flags |= 2
+		}
+		buf = append(buf, flags)
+		buf = binary.AppendUvarint(buf, uint64(node.getHeight()))
+		if buf, err = tree.keyCodec.Append(buf, node.key()); err != nil {
+			return false
+		}
+		buf, err = tree.valueCodec.Append(buf, node.value())
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf)), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// It replaces the contents of the tree with the tree encoded by MarshalBinary, rebuilding its exact shape
+// in O(n) without rotations. The tree must already have a comparator and the Codecs the data was encoded with.
+// The function checks the checksum, that the keys are in ascending order, and that the stored heights match the shape and every balance factor is within one;
+// it returns an error, leaving the tree unchanged, if any check fails.
+func (tree *AVL) UnmarshalBinary(data []byte) error {
+	if tree.keyCodec == nil || tree.valueCodec == nil || tree.comparator == nil {
+		return fmt.Errorf("ENCODING ERROR: the tree needs a comparator and codecs before decoding into it")
+	}
+	header := len(binaryMagic) + 1
+	if len(data) < header+crc32.Size || string(data[:len(binaryMagic)]) != string(binaryMagic) {
+		return fmt.Errorf("ENCODING ERROR: not an encoded AVL")
+	}
+	if data[len(binaryMagic)] != binaryVersion {
+		return fmt.Errorf("ENCODING ERROR: unsupported version %d", data[len(binaryMagic)])
+	}
+	body := data[:len(data)-crc32.Size]
+	if binary.LittleEndian.Uint32(data[len(body):]) != crc32.ChecksumIEEE(body) {
+		return fmt.Errorf("ENCODING ERROR: checksum mismatch")
+	}
+	d := &decoder{tree: tree, data: body, pos: header}
+	count, err := d.uvarint()
+	if err != nil {
+		return err
+	}
+	// every node takes at least two bytes, for its flags and height
+	if count > uint64(len(body)-d.pos)/2 {
+		return fmt.Errorf("ENCODING ERROR: %d nodes do not fit in %d bytes", count, len(body)-d.pos)
+	}
+	d.count = int(count)
+	var root *Node
+	if count > 0 {
+		if root, err = d.node(maxHeight(d.count)); err != nil {
+			return err
+		}
+	}
+	if uint64(d.nodes) != count || d.pos != len(body) {
+		return fmt.Errorf("ENCODING ERROR: %d nodes and %d trailing bytes, want %d nodes", d.nodes, len(body)-d.pos, count)
+	}
+	if err := d.checkOrder(root); err != nil {
+		return err
+	}
+	tree.setRoot(root)
+	tree.setSize(d.nodes)
+
+	return nil
+}
+
+// decoder reads the nodes of an encoded AVL.
+type decoder struct {
+	tree  *AVL   // the tree being decoded into, for its comparator, codecs, and aggregate
+	data  []byte // the encoding without its checksum
+	pos   int    // the offset of the next unread byte
+	nodes int    // the number of nodes decoded so far
+	count int    // the number of nodes the encoding claims to hold
+}
+
+// maxHeight returns the greatest height of an AVL tree with n nodes.
+// The smallest AVL tree of height h has one node more than the smallest ones of heights h-1 and h-2 together.
+func maxHeight(n int) int {
+	height, smallest, previous := 0, 0, 0
+	for next := 1; next <= n; next = smallest + previous + 1 {
+		height, smallest, previous = height+1, next, smallest
+	}
+
+	return height
+}
+
+// uvarint reads an unsigned varint.
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("ENCODING ERROR: invalid varint at byte %d", d.pos)
+	}
+	d.pos += n
+
+	return v, nil
+}
+
+// decode reads a key or value with codec.
+func (d *decoder) decode(codec trees.Codec) (interface{}, error) {
+	v, n, err := codec.Decode(d.data[d.pos:])
+	if err != nil {
+		return nil, fmt.Errorf("ENCODING ERROR: at byte %d: %w", d.pos, err)
+	}
+	d.pos += n
+
+	return v, nil
+}
+
+// node reads a node and, recursively, its subtrees, and returns the node.
+// The node's stored height must be at most limit, and each child's below its parent's, so the recursion
+// is never deeper than the height of an AVL tree with the claimed number of nodes.
+func (d *decoder) node(limit int) (*Node, error) {
+	if d.pos >= len(d.data) {
+		return nil, fmt.Errorf("ENCODING ERROR: unexpected end of data")
+	}
+	if d.nodes >= d.count {
+		return nil, fmt.Errorf("ENCODING ERROR: more than %d nodes", d.count)
+	}
+	flags := d.data[d.pos]
+	if flags&^3 != 0 {
+		return nil, fmt.Errorf("ENCODING ERROR: invalid flags at byte %d", d.pos)
+	}
+	d.pos++
+	height, err := d.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	if height < 1 || height > uint64(limit) {
+		return nil, fmt.Errorf("ENCODING ERROR: height %d at byte %d is not between 1 and %d", height, d.pos, limit)
+	}
+	key, err := d.decode(d.tree.keyCodec)
+	if err != nil {
+		return nil, err
+	}
+	value, err := d.decode(d.tree.valueCodec)
+	if err != nil {
+		return nil, err
+	}
+	d.nodes++
+	node := NewNode(key, value)
+	if flags&1 != 0 {
+		left, err := d.node(int(height) - 1)
+		if err != nil {
+			return nil, err
+		}
+		node.setLeftChild(left)
+		left.setParent(node)
+	}
+	if flags&2 != 0 {
+		right, err := d.node(int(height) - 1)
+		if err != nil {
+			return nil, err
+		}
+		node.setRightChild(right)
+		right.setParent(node)
+	}
+	node.updateHeight()
+	if uint64(node.getHeight()) != height || node.BalanceFactor() < -1 || node.BalanceFactor() > 1 {
+		return nil, fmt.Errorf("ENCODING ERROR: node %v has an invalid height", key)
+	}
+	d.tree.refresh(node)
+
+	return node, nil
+}
+
+// checkOrder returns an error unless the keys of the subtree rooted at root are in strictly ascending order.
+func (d *decoder) checkOrder(root *Node) error {
+	if root == nil {
+		return nil
+	}
+	prev := root.subtreeMin()
+	for node := prev.successor(); node != nil; prev, node = node, node.successor() {
+		if d.tree.comparator(prev.key(), node.key()) >= 0 {
+			return fmt.Errorf("ENCODING ERROR: key %v is not greater than the key before it", node.key())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package avl
+
+import (
+	"github.com/chancetudor/trees"
+	"github.com/emirpasic/gods/utils"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// Concurrent is an ordered map for many goroutines, built from path-copied AVL trees.
+// Its keys are divided into shards by the bounds passed to NewConcurrent, and each shard holds the root
+// of an immutable AVL tree behind an atomic pointer:
+//
+//   - A write locks only the shard of its key, builds a new root by copying the O(log n) nodes
+//     on the path to the change, and publishes it with one atomic store.
+//     Writers to different shards run in parallel.
+//   - A read loads the root of its key's shard and searches it without locking, so readers never wait for
+//     writers or for each other, and always see a complete tree.
+//
+// Insert, Update, Delete, Search, and ReturnNodeValue are linearizable.
+// Size, IsEmpty, Clear, and Range visit the shards one at a time, so they are not atomic across shards
+// while other goroutines write; Range sees a consistent snapshot of each shard it visits.
+// Writers scale with the number of shards their keys spread over, so the bounds should split the keys
+// the writers actually use into roughly equal parts.
+// The bounds are fixed when the Concurrent is made: shards are never split or merged as the keys change.
+// Skewed or ever-increasing keys, such as timestamps or auto-increment IDs, all land in one shard,
+// whose writers then run one at a time, as they would behind a single lock; reads still never block.
+type Concurrent struct {
+	comparator utils.Comparator  // the key comparator
+	bounds     []interface{}     // the first key of every shard but the first, in ascending order
+	shards     []concurrentShard // the shards, in key order
+}
+
+// concurrentShard is one key range of a Concurrent.
+type concurrentShard struct {
+	mu   sync.Mutex            // serializes the shard's writers
+	root atomic.Pointer[pnode] // the root of the shard's current tree, read without locking
+	_    [48]byte              // pads the shard to 64 bytes, so shards written by different cores do not share a cache line
+}
+
+// NewConcurrent takes a comparator and the keys at which new shards start, in ascending order,
+// and returns a pointer to an empty Concurrent with one more shard than there are bounds.
+// Shard i holds the keys from bounds[i-1], inclusive, up to bounds[i], exclusive.
+// The function returns an UnsortedError or a DuplicateError if the bounds are not strictly ascending.
+func NewConcurrent(comparator utils.Comparator, bounds ...interface{}) (*Concurrent, error) {
+	for i := 1; i < len(bounds); i++ {
+		compare := comparator(bounds[i-1], bounds[i])
+		switch {
+		case compare == 0:
+			return nil, NewDuplicateError(bounds[i])
+		case compare > 0:
+			return nil, NewUnsortedError(bounds[i])
+		}
+	}
+
+	return &Concurrent{
+		comparator: comparator,
+		bounds:     append([]interface{}(nil), bounds...),
+		shards:     make([]concurrentShard, len(bounds)+1),
+	}, nil
+}
+
+// IntBounds returns the bounds that divide the int keys from lo up to hi into the given number of shards
+// of equal width, for NewConcurrent with utils.IntComparator. Keys outside the range fall into the first or last shard.
+func IntBounds(lo, hi, shards int) []interface{} {
+	bounds := make([]interface{}, 0, max(shards-1, 0))
+	for i := 1; i < shards; i++ {
+		bounds = append(bounds, lo+(hi-lo)*i/shards)
+	}
+
+	return bounds
+}
+
+// shard returns the shard that holds key.
+func (c *Concurrent) shard(key interface{}) *concurrentShard {
+	i := sort.Search(len(c.bounds), func(i int) bool {
+		return c.comparator(key, c.bounds[i]) < 0
+	})
+
+	return &c.shards[i]
+}
+
+// write replaces the tree of key's shard with the tree change returns, holding the shard's lock.
+// If change returns an error, the shard is left unchanged and write returns the error.
+func (c *Concurrent) write(key interface{}, change func(root *pnode) (*pnode, error)) error {
+	shard := c.shard(key)
+	shard.mu.Lock()
+	defer shard.mu.Unlock()
+	root, err := change(shard.root.Load())
+	if err != nil {
+		return err
+	}
+	shard.root.Store(root)
+
+	return nil
+}
+
+// Insert takes a key and a value and inserts a new entry with that key and value.
+// The function returns the inserted key or an error, if there was one.
+func (c *Concurrent) Insert(key, value interface{}) (interface{}, error) {
+	err := c.write(key, func(root *pnode) (*pnode, error) {
+		return pinsert(c.comparator, root, key, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Update takes a key and a value and replaces the value of the entry with the existing key.
+// Returns the new value or an error, if there was one.
+func (c *Concurrent) Update(key, value interface{}) (interface{}, error) {
+	err := c.write(key, func(root *pnode) (*pnode, error) {
+		return pupdate(c.comparator, root, key, value)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// Delete takes a key and removes its entry.
+// The function returns the key of the deleted entry and an error, if there was one.
+func (c *Concurrent) Delete(key interface{}) (interface{}, error) {
+	var removed *pnode
+	err := c.write(key, func(root *pnode) (*pnode, error) {
+		newRoot, node, err := pdelete(c.comparator, root, key)
+		removed = node
+		return newRoot, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return removed.key, nil
+}
+
+// Search takes a key and returns a boolean, stating whether the key was found or not. It never blocks.
+func (c *Concurrent) Search(key interface{}) bool {
+	return pfind(c.comparator, c.shard(key).root.Load(), key) != nil
+}
+
+// ReturnNodeValue takes a key and returns the value associated with the key or an error, if there was one.
+// It never blocks.
+func (c *Concurrent) ReturnNodeValue(key interface{}) (interface{}, error) {
+	node := pfind(c.comparator, c.shard(key).root.Load(), key)
+	if node == nil {
+		return nil, NewNilNodeError(key)
+	}
+
+	return node.value, nil
+}
+
+// Size returns the number of entries, adding up the shards one at a time without blocking.
+func (c *Concurrent) Size() int {
+	size := 0
+	for i := range c.shards {
+		size += c.shards[i].root.Load().subtreeSize()
+	}
+
+	return size
+}
+
+// IsEmpty returns a boolean stating whether the map is empty or not.
+func (c *Concurrent) IsEmpty() bool {
+	for i := range c.shards {
+		if c.shards[i].root.Load() != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Clear removes every entry, emptying the shards one at a time.
+func (c *Concurrent) Clear() {
+	for i := range c.shards {
+		shard := &c.shards[i]
+		shard.mu.Lock()
+		shard.root.Store(nil)
+		shard.mu.Unlock()
+	}
+}
+
+// Range calls fn, in ascending key order, for every entry whose key lies between lo and hi, until fn returns false.
+// Each bound may be inclusive, exclusive, or unbounded; see package trees.
+// Range reads a snapshot of each shard without blocking, so fn may modify the map.
+func (c *Concurrent) Range(lo, hi trees.Bound, fn func(key, value interface{}) bool) {
+	for i := range c.shards {
+		// shard i holds the keys from bounds[i-1] up to bounds[i], so skip the shards outside the range
+		if i > 0 && !hi.UpperContains(c.comparator, c.bounds[i-1]) {
+			return
+		}
+		if i < len(c.bounds) && lo.Kind != trees.Unbounded && c.comparator(lo.Key, c.bounds[i]) >= 0 {
+			continue
+		}
+		if !prange(c.comparator, c.shards[i].root.Load(), lo, hi, fn) {
+			return
+		}
+	}
+}
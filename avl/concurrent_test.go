@@ -0,0 +1,499 @@
+package avl
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/treetest"
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+// newTestConcurrent returns an empty Concurrent over int keys with shards starting at 8, 16, and 32,
+// so the keys the model tests use spread over every shard.
+func newTestConcurrent(t testing.TB) *Concurrent {
+	c, err := NewConcurrent(utils.IntComparator, 8, 16, 32)
+	if err != nil {
+		t.Fatalf("NewConcurrent() error = %v", err)
+	}
+
+	return c
+}
+
+// checkPNodes returns an error unless the subtree rooted at node is a valid AVL tree
+// whose stored heights and sizes are right and whose keys all lie between lo and hi.
+func checkPNodes(node *pnode, lo, hi trees.Bound) error {
+	if node == nil {
+		return nil
+	}
+	if !lo.LowerContains(utils.IntComparator, node.key) || !hi.UpperContains(utils.IntComparator, node.key) {
+		return fmt.Errorf("key %v is out of order or in the wrong shard", node.key)
+	}
+	if err := checkPNodes(node.left, lo, trees.Excluding(node.key)); err != nil {
+		return err
+	}
+	if err := checkPNodes(node.right, trees.Excluding(node.key), hi); err != nil {
+		return err
+	}
+	lh, rh := node.left.getHeight(), node.right.getHeight()
+	switch {
+	case node.height != 1+max(lh, rh):
+		return fmt.Errorf("key %v: stored height %d, want %d", node.key, node.height, 1+max(lh, rh))
+	case lh-rh > 1 || rh-lh > 1:
+		return fmt.Errorf("key %v: subtree heights %d and %d", node.key, lh, rh)
+	case node.size != 1+node.left.subtreeSize()+node.right.subtreeSize():
+		return fmt.Errorf("key %v: stored size %d", node.key, node.size)
+	}
+
+	return nil
+}
+
+// checkConcurrent checks the tree of every shard of a Concurrent and that it holds only the shard's keys.
+func checkConcurrent(m trees.OrderedMap) error {
+	c := m.(*Concurrent)
+	for i := range c.shards {
+		lo, hi := trees.Bound{}, trees.Bound{}
+		if i > 0 {
+			lo = trees.Including(c.bounds[i-1])
+		}
+		if i < len(c.bounds) {
+			hi = trees.Excluding(c.bounds[i])
+		}
+		if err := checkPNodes(c.shards[i].root.Load(), lo, hi); err != nil {
+			return fmt.Errorf("shard %d: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+func TestConcurrent_Model(t *testing.T) {
+	newMap := func() trees.OrderedMap { return newTestConcurrent(t) }
+	treetest.TestOrderedMap(t, newMap)
+	treetest.TestModel(t, newMap, checkConcurrent)
+}
+
+func FuzzConcurrent(f *testing.F) {
+	treetest.Fuzz(f, func() trees.OrderedMap { return newTestConcurrent(f) }, checkConcurrent)
+}
+
+func TestConcurrent_Bounds(t *testing.T) {
+	if _, err := NewConcurrent(utils.IntComparator, 1, 3, 2); err == nil {
+		t.Errorf("NewConcurrent() with unsorted bounds returned no error")
+	}
+	if _, err := NewConcurrent(utils.IntComparator, 1, 1); err == nil {
+		t.Errorf("NewConcurrent() with duplicate bounds returned no error")
+	}
+	if got := IntBounds(0, 100, 4); len(got) != 3 || got[0] != 25 || got[1] != 50 || got[2] != 75 {
+		t.Errorf("IntBounds(0, 100, 4) = %v, want [25 50 75]", got)
+	}
+	if got := IntBounds(0, 100, 1); len(got) != 0 {
+		t.Errorf("IntBounds(0, 100, 1) = %v, want no bounds", got)
+	}
+	if size := unsafe.Sizeof(concurrentShard{}); size != 64 {
+		t.Errorf("a shard takes %d bytes, want 64", size)
+	}
+
+	c := newTestConcurrent(t)
+	for key := -10; key < 50; key++ {
+		c.Insert(key, key)
+	}
+	for i, want := range []int{18, 8, 16, 18} {
+		if got := c.shards[i].root.Load().subtreeSize(); got != want {
+			t.Errorf("shard %d holds %d keys, want %d", i, got, want)
+		}
+	}
+	if err := checkConcurrent(c); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConcurrent_Range(t *testing.T) {
+	c, _ := NewConcurrent(utils.IntComparator, IntBounds(0, 200, 4)...)
+	for _, key := range rand.New(rand.NewSource(1)).Perm(200) {
+		c.Insert(key, -key)
+	}
+	tests := []struct {
+		lo, hi     trees.Bound
+		first, end int
+	}{
+		{trees.Bound{}, trees.Bound{}, 0, 200},
+		{trees.Including(50), trees.Excluding(100), 50, 100},
+		{trees.Excluding(49), trees.Including(150), 50, 151},
+		{trees.Including(-5), trees.Including(10), 0, 11},
+		{trees.Excluding(120), trees.Bound{}, 121, 200},
+		{trees.Including(300), trees.Bound{}, 0, 0},
+		{trees.Bound{}, trees.Excluding(0), 0, 0},
+	}
+	for _, tt := range tests {
+		var got []interface{}
+		c.Range(tt.lo, tt.hi, func(key, value interface{}) bool {
+			if value != -key.(int) {
+				t.Errorf("Range() passed %v: %v", key, value)
+			}
+			got = append(got, key)
+			return true
+		})
+		if len(got) != tt.end-tt.first {
+			t.Fatalf("Range(%v, %v) visited %d keys, want %d", tt.lo, tt.hi, len(got), tt.end-tt.first)
+		}
+		for i, key := range got {
+			if key != tt.first+i {
+				t.Fatalf("Range(%v, %v) key %d = %v, want %d", tt.lo, tt.hi, i, key, tt.first+i)
+			}
+		}
+	}
+
+	visited := 0
+	c.Range(trees.Bound{}, trees.Bound{}, func(key, value interface{}) bool {
+		visited++
+		return key != 60
+	})
+	if visited != 61 {
+		t.Errorf("Range() visited %d keys after fn returned false at 60, want 61", visited)
+	}
+}
+
+func TestConcurrent_Snapshot(t *testing.T) {
+	c := newTestConcurrent(t)
+	for key := 16; key < 32; key++ {
+		c.Insert(key, key)
+	}
+	old := c.shards[2].root.Load()
+	c.Insert(20, 0)
+	c.Update(17, 0)
+	c.Delete(24)
+	c.Delete(25)
+	c.Insert(31, 0)
+	c.Insert(30, 0)
+
+	// the old tree is untouched, while the new one shares its unchanged subtrees
+	want := 16
+	prange(utils.IntComparator, old, trees.Bound{}, trees.Bound{}, func(key, value interface{}) bool {
+		if key != want || value != want {
+			t.Fatalf("the old tree holds %v: %v, want %d: %d", key, value, want, want)
+		}
+		want++
+		return true
+	})
+	if want != 32 || old.subtreeSize() != 16 || checkPNodes(old, trees.Bound{}, trees.Bound{}) != nil {
+		t.Errorf("the old tree changed")
+	}
+	if value, _ := c.ReturnNodeValue(17); value != 0 || c.Search(24) || c.Size() != 14 {
+		t.Errorf("the new tree is missing changes")
+	}
+}
+
+// event is one operation of a concurrent history, for the linearizability check.
+type event struct {
+	kind  treetest.OpKind
+	value int   // the value written, or the value read
+	ok    bool  // whether the operation succeeded or, for a search, found the key
+	call  int64 // the logical time the operation started
+	ret   int64 // the logical time the operation returned
+}
+
+// linearizable reports whether the history of one key can be ordered into a sequence
+// that respects real time, in which each operation returned what a map would have returned.
+// Operations on different keys are independent, so checking each key alone checks the whole map.
+// It searches the possible orders depth first, remembering the states already ruled out (Wing and Gong's algorithm).
+func linearizable(history []event) bool {
+	type state struct {
+		done    uint64 // the set of linearized events
+		present bool
+		value   int
+	}
+	all := uint64(1)<<len(history) - 1
+	failed := make(map[state]bool)
+	var search func(s state) bool
+	search = func(s state) bool {
+		if s.done == all {
+			return true
+		}
+		if failed[s] {
+			return false
+		}
+		// the next event must have started before every other pending event returned
+		firstReturn := int64(1 << 62)
+		for i, e := range history {
+			if s.done&(1<<i) == 0 && e.ret < firstReturn {
+				firstReturn = e.ret
+			}
+		}
+		for i, e := range history {
+			if s.done&(1<<i) != 0 || e.call > firstReturn {
+				continue
+			}
+			next := state{done: s.done | 1<<i, present: s.present, value: s.value}
+			switch e.kind {
+			case treetest.OpInsert:
+				if e.ok == s.present {
+					continue
+				}
+				if e.ok {
+					next.present, next.value = true, e.value
+				}
+			case treetest.OpUpdate:
+				if e.ok != s.present {
+					continue
+				}
+				if e.ok {
+					next.value = e.value
+				}
+			case treetest.OpDelete:
+				if e.ok != s.present {
+					continue
+				}
+				next.present = false
+			case treetest.OpSearch:
+				if e.ok != s.present || (e.ok && e.value != s.value) {
+					continue
+				}
+			}
+			if search(next) {
+				return true
+			}
+		}
+		failed[s] = true
+
+		return false
+	}
+
+	return search(state{})
+}
+
+func TestConcurrent_LinearizableCheck(t *testing.T) {
+	insert := event{kind: treetest.OpInsert, value: 1, ok: true, call: 1, ret: 4}
+	tests := []struct {
+		name    string
+		history []event
+		want    bool
+	}{
+		{"search overlaps insert", []event{insert, {kind: treetest.OpSearch, ok: false, call: 2, ret: 3}}, true},
+		{"search after insert", []event{insert, {kind: treetest.OpSearch, ok: false, call: 5, ret: 6}}, false},
+		{"stale read", []event{insert, {kind: treetest.OpUpdate, value: 2, ok: true, call: 5, ret: 6},
+			{kind: treetest.OpSearch, value: 1, ok: true, call: 7, ret: 8}}, false},
+		{"two inserts succeed", []event{insert, {kind: treetest.OpInsert, value: 2, ok: true, call: 2, ret: 3}}, false},
+		{"delete between", []event{insert, {kind: treetest.OpInsert, value: 2, ok: true, call: 2, ret: 9},
+			{kind: treetest.OpDelete, ok: true, call: 5, ret: 6}}, true},
+	}
+	for _, tt := range tests {
+		if got := linearizable(tt.history); got != tt.want {
+			t.Errorf("%s: linearizable() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConcurrent_Linearizable(t *testing.T) {
+	const rounds, goroutines, opsPerGoroutine, keys = 200, 4, 48, 4
+	var clock atomic.Int64
+	for round := 0; round < rounds; round++ {
+		c := newTestConcurrent(t)
+		histories := make([][][]event, goroutines) // by goroutine, then key
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			histories[g] = make([][]event, keys)
+			go func(g int) {
+				defer wg.Done()
+				r := rand.New(rand.NewSource(int64(round*goroutines + g)))
+				for i := 0; i < opsPerGoroutine; i++ {
+					// every goroutine cycles through the keys, so each key's history has the same length;
+					// keys 7 and 8 sit on either side of a shard boundary
+					key := 7 + (g+i)%keys
+					e := event{kind: treetest.OpKind(r.Intn(4)), value: g*1000 + i}
+					e.call = clock.Add(1)
+					switch e.kind {
+					case treetest.OpInsert:
+						_, err := c.Insert(key, e.value)
+						e.ok = err == nil
+					case treetest.OpUpdate:
+						_, err := c.Update(key, e.value)
+						e.ok = err == nil
+					case treetest.OpDelete:
+						_, err := c.Delete(key)
+						e.ok = err == nil
+					case treetest.OpSearch:
+						value, err := c.ReturnNodeValue(key)
+						e.ok = err == nil
+						if e.ok {
+							e.value = value.(int)
+						}
+					}
+					e.ret = clock.Add(1)
+					histories[g][key-7] = append(histories[g][key-7], e)
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		for key := 0; key < keys; key++ {
+			var history []event
+			for g := range histories {
+				history = append(history, histories[g][key]...)
+			}
+			if len(history) > 64 {
+				t.Fatalf("a history of %d events is too long to check", len(history))
+			}
+			if !linearizable(history) {
+				t.Fatalf("round %d: the history of key %d is not linearizable: %+v", round, key+7, history)
+			}
+		}
+	}
+}
+
+func TestConcurrent_Stress(t *testing.T) {
+	const writers, readers, keys = 8, 8, 1024
+	iterations := 20000
+	if testing.Short() {
+		iterations = 2000
+	}
+	c, _ := NewConcurrent(utils.IntComparator, IntBounds(0, keys, 16)...)
+	var wg sync.WaitGroup
+	var stop atomic.Bool
+	// writer w owns the keys congruent to w and stores ever larger versions in them,
+	// so a reader must never see a key's version go backwards
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(int64(w)))
+			for version := 1; version <= iterations; version++ {
+				key := w + writers*r.Intn(keys/writers)
+				switch r.Intn(3) {
+				case 0:
+					c.Delete(key)
+				default:
+					if _, err := c.Update(key, version); err != nil {
+						c.Insert(key, version)
+					}
+				}
+			}
+		}(w)
+	}
+	var readersDone sync.WaitGroup
+	for g := 0; g < readers; g++ {
+		readersDone.Add(1)
+		go func(g int) {
+			defer readersDone.Done()
+			r := rand.New(rand.NewSource(int64(100 + g)))
+			seen := make(map[int]int)
+			for !stop.Load() {
+				key := r.Intn(keys)
+				if value, err := c.ReturnNodeValue(key); err == nil {
+					if value.(int) < seen[key] {
+						t.Errorf("key %d went back from version %d to %v", key, seen[key], value)
+						return
+					}
+					seen[key] = value.(int)
+				}
+				if r.Intn(64) == 0 {
+					// every shard a range passes through is a complete, balanced tree
+					prev := -1
+					c.Range(trees.Including(r.Intn(keys)), trees.Bound{}, func(key, value interface{}) bool {
+						if key.(int) <= prev {
+							t.Errorf("Range() passed %v after %d", key, prev)
+						}
+						prev = key.(int)
+						return true
+					})
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	stop.Store(true)
+	readersDone.Wait()
+
+	if err := checkConcurrent(c); err != nil {
+		t.Fatal(err)
+	}
+	size := 0
+	c.Range(trees.Bound{}, trees.Bound{}, func(key, value interface{}) bool {
+		size++
+		return true
+	})
+	if size != c.Size() {
+		t.Errorf("Range() visited %d entries, Size() = %d", size, c.Size())
+	}
+}
+
+// concurrentMaps are the maps BenchmarkConcurrent compares: a whole AVL behind one reader/writer lock,
+// and Concurrent with one shard and with many.
+var concurrentMaps = []struct {
+	name string
+	new  func(keys int) trees.OrderedMap
+}{
+	{"rwmutex", func(int) trees.OrderedMap { return trees.NewConcurrent(NewWithIntComparator()) }},
+	{"shards=1", func(int) trees.OrderedMap { c, _ := NewConcurrent(utils.IntComparator); return c }},
+	{"shards=64", func(keys int) trees.OrderedMap {
+		c, _ := NewConcurrent(utils.IntComparator, IntBounds(0, keys, 64)...)
+		return c
+	}},
+}
+
+// BenchmarkConcurrent measures throughput under parallel load on a map of 100000 keys
+// for a read-mostly, a mixed, and a write-only workload. Run it at several core counts to see how each map scales:
+//
+//	go test ./avl -run '^$' -bench Concurrent -cpu 1,2,4,8,16,32
+func BenchmarkConcurrent(b *testing.B) {
+	const keys = 100000
+	for _, workload := range []struct {
+		name        string
+		readPercent int
+	}{{"reads=90%", 90}, {"reads=50%", 50}, {"reads=0%", 0}} {
+		for _, m := range concurrentMaps {
+			b.Run(workload.name+"/"+m.name, func(b *testing.B) {
+				tree := m.new(keys)
+				for key := 0; key < keys; key += 2 {
+					tree.Insert(key, key)
+				}
+				var seed atomic.Int64
+				b.ReportAllocs()
+				b.ResetTimer()
+				b.RunParallel(func(pb *testing.PB) {
+					r := rand.New(rand.NewSource(seed.Add(1)))
+					for pb.Next() {
+						key := r.Intn(keys)
+						switch op := r.Intn(100); {
+						case op < workload.readPercent:
+							tree.Search(key)
+						case op%2 == 0:
+							tree.Insert(key, key)
+						default:
+							tree.Delete(key)
+						}
+					}
+				})
+			})
+		}
+	}
+}
+
+// BenchmarkConcurrent_Monotonic inserts ever-increasing keys, as timestamps or auto-increment IDs would be,
+// from every goroutine into a map of 100000 keys. The keys all lie past the last bound,
+// so with 64 shards every writer still lands on the last one, and shards=64 scales no better than shards=1.
+func BenchmarkConcurrent_Monotonic(b *testing.B) {
+	const keys = 100000
+	for _, m := range concurrentMaps {
+		b.Run(m.name, func(b *testing.B) {
+			tree := m.new(keys)
+			for key := 0; key < keys; key += 2 {
+				tree.Insert(key, key)
+			}
+			var next atomic.Int64
+			next.Store(keys)
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					key := int(next.Add(1))
+					tree.Insert(key, key)
+				}
+			})
+		})
+	}
+}
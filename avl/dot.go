@@ -0,0 +1,47 @@
+package avl
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/internal/dot"
+	"io"
+	"strings"
+)
+
+// WriteDOT writes the structure of the tree to w in the Graphviz DOT language, as one directed graph
+// with an edge from every node to each of its children.
+// Every node is also labeled with its stored height and its BalanceFactor.
+// opts names the graph, chooses the node labels, and says whether nil leaves are drawn; see trees.DOTOptions.
+// The function returns the error from w, if there was one.
+func (tree *AVL) WriteDOT(w io.Writer, opts trees.DOTOptions) error {
+	var out strings.Builder
+	fmt.Fprintf(&out, "digraph %s {\n", dot.GraphName(opts))
+	if !tree.IsEmpty() {
+		out.WriteString("\tordering=out;\n\tnode [shape=ellipse];\n")
+	}
+	ids := make(map[*Node]int, tree.Size())
+	tree.walk(trees.PreOrder, func(node *Node) bool {
+		ids[node] = len(ids)
+		return true
+	})
+	nils := 0
+	tree.walk(trees.PreOrder, func(node *Node) bool {
+		id := ids[node]
+		fmt.Fprintf(&out, "\tn%d [label=\"%s\\nh=%d bf=%d\"];\n",
+			id, dot.NodeLabel(opts, node.key(), node.value()), node.getHeight(), node.BalanceFactor())
+		for _, child := range []*Node{node.leftChild(), node.rightChild()} {
+			switch {
+			case child != nil:
+				fmt.Fprintf(&out, "\tn%d -> n%d;\n", id, ids[child])
+			case opts.NilLeaves:
+				fmt.Fprintf(&out, "\tnil%d [shape=point];\n\tn%d -> nil%d;\n", nils, id, nils)
+				nils++
+			}
+		}
+		return true
+	})
+	out.WriteString("}\n")
+	_, err := io.WriteString(w, out.String())
+
+	return err
+}
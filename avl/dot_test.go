@@ -0,0 +1,70 @@
+package avl
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"strings"
+	"testing"
+)
+
+func TestAVL_WriteDOT(t *testing.T) {
+	tree := newShuffledTree(50)
+	var out strings.Builder
+	if err := tree.WriteDOT(&out, trees.DOTOptions{}); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "digraph \"tree\" {\n") || !strings.HasSuffix(out.String(), "}\n") {
+		t.Errorf("WriteDOT() wrote a malformed graph:\n%s", out.String())
+	}
+	if got := strings.Count(out.String(), " -> "); got != tree.Size()-1 {
+		t.Errorf("WriteDOT() wrote %d edges, want %d", got, tree.Size()-1)
+	}
+	if got := strings.Count(out.String(), "[label="); got != tree.Size() {
+		t.Errorf("WriteDOT() wrote %d nodes, want %d", got, tree.Size())
+	}
+	// the root is declared first and its key is the default label
+	if !strings.Contains(out.String(), fmt.Sprintf("n0 [label=\"%d", tree.Root().key())) {
+		t.Errorf("WriteDOT() did not label the root with its key:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), fmt.Sprintf("\\nh=%d bf=%d", tree.Root().getHeight(), tree.Root().BalanceFactor())) {
+		t.Errorf("WriteDOT() did not annotate the root with its height and balance factor")
+	}
+}
+
+func TestAVL_WriteDOTOptions(t *testing.T) {
+	tree := newShuffledTree(20)
+	var out strings.Builder
+	opts := trees.DOTOptions{
+		Name:      "my \"tree\"",
+		NilLeaves: true,
+		Label: func(key, value interface{}) string {
+			return fmt.Sprintf("%v=\"%v\"", key, value)
+		},
+	}
+	if err := tree.WriteDOT(&out, opts); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	if !strings.HasPrefix(out.String(), "digraph \"my \\\"tree\\\"\" {") {
+		t.Errorf("WriteDOT() did not escape the graph name:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "[label=\"4=\\\"40\\\"") {
+		t.Errorf("WriteDOT() did not use and escape the custom label:\n%s", out.String())
+	}
+	// every node has two child slots, and the nil ones become points
+	if got := strings.Count(out.String(), " -> "); got != 2*tree.Size() {
+		t.Errorf("WriteDOT() with nil leaves wrote %d edges, want %d", got, 2*tree.Size())
+	}
+	if got := strings.Count(out.String(), "[shape=point]"); got != tree.Size()+1 {
+		t.Errorf("WriteDOT() drew %d nil leaves, want %d", got, tree.Size()+1)
+	}
+}
+
+func TestAVL_WriteDOTEmpty(t *testing.T) {
+	var out strings.Builder
+	if err := NewWithIntComparator().WriteDOT(&out, trees.DOTOptions{Name: "empty"}); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	if out.String() != "digraph \"empty\" {\n}\n" {
+		t.Errorf("WriteDOT() of an empty tree = %q", out.String())
+	}
+}
@@ -0,0 +1,25 @@
+package avl
+
+import (
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/treetest"
+	"testing"
+)
+
+// newModelTree returns an empty AVL as a trees.OrderedMap for the model-based tests.
+func newModelTree() trees.OrderedMap {
+	return NewWithIntComparator()
+}
+
+// validate checks every invariant of an AVL after each operation of a model-based test.
+func validate(m trees.OrderedMap) error {
+	return m.(*AVL).Validate()
+}
+
+func TestAVL_Model(t *testing.T) {
+	treetest.TestModel(t, newModelTree, validate)
+}
+
+func FuzzAVL(f *testing.F) {
+	treetest.Fuzz(f, newModelTree, validate)
+}
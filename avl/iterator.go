@@ -0,0 +1,106 @@
+package avl
+
+// position records where an Iterator sits relative to the entries of the tree.
+type position int
+
+const (
+	beforeFirst position = iota // before the smallest entry
+	onNode                      // on an entry
+	afterLast                   // after the largest entry
+)
+
+// Iterator walks the entries of an AVL in key order, in either direction.
+// Each step follows parent pointers instead of recursing, so it takes O(1) amortized time.
+// A new Iterator sits before the first entry; call Next or First to move onto it.
+// Inserting into or deleting from the tree invalidates the Iterator.
+type Iterator struct {
+	tree     *AVL     // the tree being iterated
+	node     *Node    // the current node, nil unless position is onNode
+	position position // where the iterator sits
+}
+
+// Iterator returns a pointer to an Iterator positioned before the first entry of the tree.
+func (tree *AVL) Iterator() *Iterator {
+	return &Iterator{
+		tree:     tree,
+		node:     nil,
+		position: beforeFirst,
+	}
+}
+
+// Next moves the iterator to the next entry in key order.
+// If the iterator sits before the first entry, Next moves it onto the first entry.
+// The function returns false, leaving the iterator after the last entry, if there is no next entry.
+func (it *Iterator) Next() bool {
+	switch it.position {
+	case beforeFirst:
+		return it.First()
+	case onNode:
+		return it.moveTo(it.node.successor(), afterLast)
+	default:
+		return false
+	}
+}
+
+// Prev moves the iterator to the previous entry in key order.
+// If the iterator sits after the last entry, Prev moves it onto the last entry.
+// The function returns false, leaving the iterator before the first entry, if there is no previous entry.
+func (it *Iterator) Prev() bool {
+	switch it.position {
+	case afterLast:
+		return it.Last()
+	case onNode:
+		return it.moveTo(it.node.predecessor(), beforeFirst)
+	default:
+		return false
+	}
+}
+
+// First moves the iterator onto the entry with the smallest key.
+// The function returns false if the tree is empty.
+func (it *Iterator) First() bool {
+	return it.moveTo(it.tree.minNode(), afterLast)
+}
+
+// Last moves the iterator onto the entry with the largest key.
+// The function returns false if the tree is empty.
+func (it *Iterator) Last() bool {
+	return it.moveTo(it.tree.maxNode(), beforeFirst)
+}
+
+// Seek moves the iterator onto the entry with the smallest key greater than or equal to key.
+// The function returns false, leaving the iterator after the last entry, if every key is smaller than key.
+func (it *Iterator) Seek(key interface{}) bool {
+	return it.moveTo(it.tree.ceilingNode(key), afterLast)
+}
+
+// Key returns the key of the current entry, or nil if the iterator is not on an entry.
+func (it *Iterator) Key() interface{} {
+	if it.position != onNode {
+		return nil
+	}
+
+	return it.node.key()
+}
+
+// Value returns the value of the current entry, or nil if the iterator is not on an entry.
+func (it *Iterator) Value() interface{} {
+	if it.position != onNode {
+		return nil
+	}
+
+	return it.node.value()
+}
+
+// moveTo sets the current node.
+// If node is nil, the iterator moves to the fallback position and the function returns false.
+func (it *Iterator) moveTo(node *Node, fallback position) bool {
+	it.node = node
+	if node == nil {
+		it.position = fallback
+		return false
+	}
+	it.position = onNode
+
+	return true
+}
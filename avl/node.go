@@ -1,12 +1,10 @@
 package avl
 
 import (
-	"fmt"
 	"github.com/emirpasic/gods/utils"
-	"math"
 )
 
-// Node stores left, right, and parent Node pointers;
+// Node stores left, right, and parent Node pointers; the height and size of the node's subtree;
 // and NodeData, containing the key and the value the caller wishes to store.
 type Node struct {
 	left   *Node
@@ -14,6 +12,8 @@ type Node struct {
 	parent *Node
 	Data   *NodeData
 	height int
+	size   int         // number of nodes in the subtree rooted at this node
+	agg    interface{} // aggregate of the subtree rooted at this node, if the tree has an Aggregate
 }
 
 // NodeData stores the key and the value of the Node.
@@ -30,6 +30,7 @@ func NewNode(k, v interface{}) *Node {
 		right:  nil,
 		parent: nil,
 		height: 1, // new node is added at leaf initially
+		size:   1,
 		Data: &NodeData{
 			Key:   k,
 			Value: v,
@@ -46,17 +47,6 @@ func (node *Node) BalanceFactor() int {
 	return node.rightChild().getHeight() - node.leftChild().getHeight()
 }
 
-// getHeight returns the getHeight of the tree from a specific node.
-func (node *Node) calculateHeight() int {
-	if node == nil {
-		return -1
-	}
-
-	return 1 + int(math.Max(
-		float64(node.leftChild().calculateHeight()),
-		float64(node.rightChild().calculateHeight())))
-}
-
 // setHeight stores the current getHeight of the node.
 func (node *Node) setHeight(h int) {
 	if node != nil {
@@ -64,6 +54,11 @@ func (node *Node) setHeight(h int) {
 	}
 }
 
+// updateHeight sets the node's height to one more than the larger height stored in its children.
+func (node *Node) updateHeight() {
+	node.setHeight(1 + max(node.leftChild().getHeight(), node.rightChild().getHeight()))
+}
+
 // getHeight returns the getHeight stored in the node.
 // if node is nil, the function returns 0.
 func (node *Node) getHeight() int {
@@ -74,34 +69,11 @@ func (node *Node) getHeight() int {
 	return 0
 }
 
-// dfs traverses the nodes in a depth-first search paradigm.
-// The function prints by converting each node's key and value to a string.
-func (node *Node) dfs() {
-	if node == nil {
-		return
-	}
-	if node.getParent() == nil {
-		fmt.Println("ROOT")
-	}
-	fmt.Println("Key = " + utils.ToString(node.Data.Key) +
-		" | " + "Value = " + utils.ToString(node.Data.Value))
-	node.leftChild().dfs()
-	node.rightChild().dfs()
-}
-
-// inOrder traverses the nodes "in order," printing every node's value in order from smallest to greatest.
-// The function prints by converting each node's key and value to a string.
-func (node *Node) inOrder() {
-	if node == nil {
-		return
-	}
-	node.leftChild().inOrder()
-	if node.getParent() == nil {
-		fmt.Println("ROOT")
-	}
-	fmt.Println("Key = " + utils.ToString(node.Data.Key) +
-		" | " + "Value = " + utils.ToString(node.Data.Value))
-	node.rightChild().inOrder()
+// describe returns the line the printing traversals write for the node:
+// its key and value converted to strings.
+func (node *Node) describe() string {
+	return "Key = " + utils.ToString(node.Data.Key) +
+		" | " + "Value = " + utils.ToString(node.Data.Value)
 }
 
 // isRoot checks to see if Node's parent is nil.
@@ -213,6 +185,20 @@ func (node *Node) value() interface{} {
 	return nil
 }
 
+// subtreeSize returns the number of nodes in the subtree rooted at the node, or 0 if the node is nil.
+func (node *Node) subtreeSize() int {
+	if node != nil {
+		return node.size
+	}
+
+	return 0
+}
+
+// updateSize sets the node's subtree size from the sizes stored in its children.
+func (node *Node) updateSize() {
+	node.size = 1 + node.leftChild().subtreeSize() + node.rightChild().subtreeSize()
+}
+
 // successor returns the node with the smallest key greater than the node the method is called on
 func (node *Node) successor() *Node {
 	// successor is the furthest left child of the right subtree
@@ -232,11 +218,11 @@ func (node *Node) successor() *Node {
 
 // predecessor returns the node with the largest key smaller than the node the method is called on
 func (node *Node) predecessor() *Node {
-	// successor is the furthest left child of the right subtree
+	// predecessor is the furthest right child of the left subtree
 	if node.leftChild() != nil {
-		return node.rightChild().subtreeMax()
+		return node.leftChild().subtreeMax()
 	}
-	// otherwise, work up and to the right of the subtrees
+	// otherwise, work up and to the left of the subtrees
 	parent := node.getParent()
 	temp := node
 	for parent != nil && temp == parent.leftChild() {
@@ -266,3 +252,62 @@ func (node *Node) subtreeMax() *Node {
 
 	return temp
 }
+
+// Left returns the node's left child, or nil if the node has none or is nil.
+// Together with Right, Parent, Key, Value, and Height, it lets callers read the shape of a tree from Root.
+// The links, heights, and subtree sizes are read-only, since only the tree's methods rebalance it.
+// The exported Data field is not: setting Data.Key through it breaks the key order, and setting Data.Value
+// leaves the aggregates of a tree from NewWithAggregate stale, so change entries with Update.
+func (node *Node) Left() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.left
+}
+
+// Right returns the node's right child, or nil if the node has none or is nil.
+func (node *Node) Right() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.right
+}
+
+// Parent returns the node's parent, or nil if the node is the root or is nil.
+func (node *Node) Parent() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.parent
+}
+
+// Key returns the node's key, or nil if the node is nil.
+func (node *Node) Key() interface{} {
+	if node == nil {
+		return nil
+	}
+
+	return node.Data.Key
+}
+
+// Value returns the node's value, or nil if the node is nil.
+func (node *Node) Value() interface{} {
+	if node == nil {
+		return nil
+	}
+
+	return node.Data.Value
+}
+
+// IsLeaf reports whether the node has no children. It returns false if the node is nil.
+func (node *Node) IsLeaf() bool {
+	return node != nil && node.left == nil && node.right == nil
+}
+
+// Height returns the height of the node's subtree: 1 for a leaf, or 0 if the node is nil.
+func (node *Node) Height() int {
+	return node.getHeight()
+}
@@ -0,0 +1,191 @@
+package avl
+
+import (
+	"github.com/chancetudor/trees"
+	"github.com/emirpasic/gods/utils"
+)
+
+// Persistent is an immutable version of an AVL tree. Insert, Update, and Delete leave the version they are called on
+// unchanged and return a new version, which copies only the O(log n) nodes on the path to the change
+// and shares every other subtree with the old version. A version can therefore be kept as a cheap
+// point-in-time snapshot, and read from any number of goroutines without locking,
+// while newer versions are built from it. Every operation keeps the O(log n) bounds of AVL.
+// Persistent implements trees.OrderedReader. Duplicates are not allowed.
+type Persistent struct {
+	root       *pnode           // the root of this version, shared with the versions built from it
+	comparator utils.Comparator // the key comparator
+}
+
+// NewPersistent returns a pointer to an empty Persistent whose keys are ordered by comparator.
+// The comparator format is taken from https://github.com/emirpasic/gods#comparator.
+func NewPersistent(comparator utils.Comparator) *Persistent {
+	return &Persistent{root: nil, comparator: comparator}
+}
+
+// NewPersistentWithIntComparator returns a pointer to an empty Persistent whose keys are ints.
+func NewPersistentWithIntComparator() *Persistent {
+	return NewPersistent(utils.IntComparator)
+}
+
+// NewPersistentWithStringComparator returns a pointer to an empty Persistent whose keys are strings.
+func NewPersistentWithStringComparator() *Persistent {
+	return NewPersistent(utils.StringComparator)
+}
+
+// Snapshot returns a Persistent holding the tree's current entries, copying its shape in O(n).
+// Later changes to the tree do not affect the snapshot, and changes to the snapshot's versions do not affect the tree.
+func (tree *AVL) Snapshot() *Persistent {
+	var copyNode func(node *Node) *pnode
+	copyNode = func(node *Node) *pnode {
+		if node == nil {
+			return nil
+		}
+		return newPNode(node.key(), node.value(), copyNode(node.leftChild()), copyNode(node.rightChild()))
+	}
+
+	return &Persistent{root: copyNode(tree.Root()), comparator: tree.comparator}
+}
+
+// version returns a new version with root as its root.
+func (tree *Persistent) version(root *pnode) *Persistent {
+	return &Persistent{root: root, comparator: tree.comparator}
+}
+
+// Insert takes a key and a value and returns a new version that also holds an entry with that key and value.
+// If the key already exists, the function returns the version it was called on and a DuplicateError.
+func (tree *Persistent) Insert(key, value interface{}) (*Persistent, error) {
+	root, err := pinsert(tree.comparator, tree.root, key, value)
+	if err != nil {
+		return tree, err
+	}
+
+	return tree.version(root), nil
+}
+
+// Update takes a key and a value and returns a new version in which the existing key maps to value.
+// If the key does not exist, the function returns the version it was called on and a NilNodeError.
+func (tree *Persistent) Update(key, value interface{}) (*Persistent, error) {
+	root, err := pupdate(tree.comparator, tree.root, key, value)
+	if err != nil {
+		return tree, err
+	}
+
+	return tree.version(root), nil
+}
+
+// Delete takes a key and returns a new version without the key's entry.
+// If the key does not exist, the function returns the version it was called on and a NilNodeError.
+func (tree *Persistent) Delete(key interface{}) (*Persistent, error) {
+	root, _, err := pdelete(tree.comparator, tree.root, key)
+	if err != nil {
+		return tree, err
+	}
+
+	return tree.version(root), nil
+}
+
+// Search takes a key and returns a boolean, stating whether the key was found or not.
+func (tree *Persistent) Search(key interface{}) bool {
+	return pfind(tree.comparator, tree.root, key) != nil
+}
+
+// ReturnNodeValue takes a key and returns the value associated with the key or an error, if there was one.
+func (tree *Persistent) ReturnNodeValue(key interface{}) (interface{}, error) {
+	node := pfind(tree.comparator, tree.root, key)
+	if node == nil {
+		return nil, NewNilNodeError(key)
+	}
+
+	return node.value, nil
+}
+
+// Size returns the number of entries in the version, in O(1).
+func (tree *Persistent) Size() int {
+	return tree.root.subtreeSize()
+}
+
+// IsEmpty returns a boolean stating whether the version is empty or not.
+func (tree *Persistent) IsEmpty() bool {
+	return tree.root == nil
+}
+
+// Min returns the entry with the smallest key in the version.
+// The boolean is false if the version is empty.
+func (tree *Persistent) Min() (interface{}, interface{}, bool) {
+	node := tree.root
+	for node != nil && node.left != nil {
+		node = node.left
+	}
+
+	return pentryOf(node)
+}
+
+// Max returns the entry with the largest key in the version.
+// The boolean is false if the version is empty.
+func (tree *Persistent) Max() (interface{}, interface{}, bool) {
+	node := tree.root
+	for node != nil && node.right != nil {
+		node = node.right
+	}
+
+	return pentryOf(node)
+}
+
+// Select takes an index i and returns the entry with the i-th smallest key, counting from 0.
+// The boolean is false if i is negative or not less than the size of the version.
+func (tree *Persistent) Select(i int) (interface{}, interface{}, bool) {
+	if i < 0 || i >= tree.Size() {
+		return nil, nil, false
+	}
+	node := tree.root
+	for node != nil {
+		leftSize := node.left.subtreeSize()
+		switch {
+		case i < leftSize:
+			node = node.left
+		case i > leftSize:
+			i -= leftSize + 1
+			node = node.right
+		default:
+			return pentryOf(node)
+		}
+	}
+
+	return nil, nil, false
+}
+
+// Rank takes a key and returns the number of keys in the version that are smaller than it.
+// The key does not need to be in the version.
+func (tree *Persistent) Rank(key interface{}) int {
+	rank := 0
+	node := tree.root
+	for node != nil {
+		compare := tree.comparator(key, node.key)
+		switch {
+		case compare < 0:
+			node = node.left
+		case compare > 0:
+			rank += node.left.subtreeSize() + 1
+			node = node.right
+		default:
+			return rank + node.left.subtreeSize()
+		}
+	}
+
+	return rank
+}
+
+// Range calls fn, in ascending key order, for every entry whose key lies between lo and hi, until fn returns false.
+// Each bound may be inclusive, exclusive, or unbounded; see package trees. Visiting k entries costs O(log n + k).
+func (tree *Persistent) Range(lo, hi trees.Bound, fn func(key, value interface{}) bool) {
+	prange(tree.comparator, tree.root, lo, hi, fn)
+}
+
+// pentryOf returns the key and value of a node and true, or nil, nil, and false if the node is nil.
+func pentryOf(node *pnode) (interface{}, interface{}, bool) {
+	if node == nil {
+		return nil, nil, false
+	}
+
+	return node.key, node.value, true
+}
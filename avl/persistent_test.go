@@ -0,0 +1,254 @@
+package avl
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/treetest"
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// checkVersion returns an error unless version is a valid AVL tree holding exactly the entries of model.
+func checkVersion(version *Persistent, model map[int]int) error {
+	if err := checkPNodes(version.root, trees.Bound{}, trees.Bound{}); err != nil {
+		return err
+	}
+	if version.Size() != len(model) || version.IsEmpty() != (len(model) == 0) {
+		return fmt.Errorf("Size() = %d, want %d", version.Size(), len(model))
+	}
+	for key, want := range model {
+		if got, err := version.ReturnNodeValue(key); err != nil || got != want {
+			return fmt.Errorf("ReturnNodeValue(%d) = %v, %v, want %d", key, got, err, want)
+		}
+	}
+
+	return nil
+}
+
+func TestPersistent_Versions(t *testing.T) {
+	type saved struct {
+		version *Persistent
+		model   map[int]int
+	}
+	var versions []saved
+	version := NewPersistentWithIntComparator()
+	model := make(map[int]int)
+	for i, op := range treetest.RandomOps(1, 5000, 300) {
+		_, exists := model[op.Key]
+		next, err := version, error(nil)
+		switch op.Kind {
+		case treetest.OpInsert:
+			next, err = version.Insert(op.Key, op.Value)
+			if err == nil {
+				model[op.Key] = op.Value
+			}
+		case treetest.OpUpdate:
+			next, err = version.Update(op.Key, op.Value)
+			if err == nil {
+				model[op.Key] = op.Value
+			}
+		case treetest.OpDelete:
+			next, err = version.Delete(op.Key)
+			delete(model, op.Key)
+		case treetest.OpSearch:
+			if version.Search(op.Key) != exists {
+				t.Fatalf("step %d: Search(%d) = %v, want %v", i, op.Key, !exists, exists)
+			}
+			continue
+		case treetest.OpClear:
+			next, model = NewPersistentWithIntComparator(), make(map[int]int)
+		}
+		if wantErr := op.Kind != treetest.OpClear && (op.Kind == treetest.OpInsert) == exists; (err != nil) != wantErr {
+			t.Fatalf("step %d: %v error = %v", i, op, err)
+		}
+		if err != nil && next != version {
+			t.Fatalf("step %d: %v failed but returned a new version", i, op)
+		}
+		version = next
+		if i%100 == 0 {
+			snapshot := make(map[int]int, len(model))
+			for key, value := range model {
+				snapshot[key] = value
+			}
+			versions = append(versions, saved{version, snapshot})
+		}
+	}
+
+	// every version still holds what it held when it was made
+	for i, v := range versions {
+		if err := checkVersion(v.version, v.model); err != nil {
+			t.Fatalf("version %d: %v", i, err)
+		}
+	}
+}
+
+func TestPersistent_Sharing(t *testing.T) {
+	old := NewPersistentWithIntComparator()
+	for key := 0; key < 1023; key++ {
+		old, _ = old.Insert(key, key)
+	}
+	oldNodes := make(map[*pnode]bool)
+	var collect func(node *pnode)
+	collect = func(node *pnode) {
+		if node != nil {
+			oldNodes[node] = true
+			collect(node.left)
+			collect(node.right)
+		}
+	}
+	collect(old.root)
+
+	for _, key := range []int{0, 511, 700, 1022} {
+		deleted, err := old.Delete(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		updated, _ := old.Update(key+1, -1)
+		inserted, _ := old.Insert(key*2+2000, 0)
+		for name, version := range map[string]*Persistent{"Delete": deleted, "Update": updated, "Insert": inserted} {
+			// the new version reaches O(log n) new nodes and shares the rest with the old one
+			copied := 0
+			var count func(node *pnode)
+			count = func(node *pnode) {
+				if node != nil && !oldNodes[node] {
+					copied++
+					count(node.left)
+					count(node.right)
+				}
+			}
+			count(version.root)
+			if limit := 2 * old.root.getHeight(); copied > limit {
+				t.Errorf("%s(%d) copied %d nodes, want at most %d", name, key, copied, limit)
+			}
+		}
+	}
+	if old.Size() != 1023 || checkPNodes(old.root, trees.Bound{}, trees.Bound{}) != nil {
+		t.Errorf("the old version changed")
+	}
+}
+
+func TestPersistent_Order(t *testing.T) {
+	version := NewPersistentWithIntComparator()
+	if _, _, ok := version.Min(); ok {
+		t.Errorf("Min() of an empty version found an entry")
+	}
+	for _, key := range rand.New(rand.NewSource(1)).Perm(100) {
+		version, _ = version.Insert(2*key, key)
+	}
+	if key, value, ok := version.Min(); !ok || key != 0 || value != 0 {
+		t.Errorf("Min() = %v, %v, %v", key, value, ok)
+	}
+	if key, value, ok := version.Max(); !ok || key != 198 || value != 99 {
+		t.Errorf("Max() = %v, %v, %v", key, value, ok)
+	}
+	for i := 0; i < 100; i++ {
+		if key, _, ok := version.Select(i); !ok || key != 2*i {
+			t.Fatalf("Select(%d) = %v, %v", i, key, ok)
+		}
+		if rank := version.Rank(2*i + 1); rank != i+1 {
+			t.Fatalf("Rank(%d) = %d, want %d", 2*i+1, rank, i+1)
+		}
+	}
+	if _, _, ok := version.Select(100); ok {
+		t.Errorf("Select(100) found an entry")
+	}
+	var keys []interface{}
+	version.Range(trees.Excluding(10), trees.Including(20), func(key, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+	if fmt.Sprint(keys) != "[12 14 16 18 20]" {
+		t.Errorf("Range((10, 20]) = %v", keys)
+	}
+}
+
+func TestPersistent_Snapshot(t *testing.T) {
+	tree := NewWithIntComparator()
+	for _, key := range rand.New(rand.NewSource(1)).Perm(500) {
+		tree.Insert(key, -key)
+	}
+	snapshot := tree.Snapshot()
+	model := make(map[int]int)
+	for key := 0; key < 500; key++ {
+		model[key] = -key
+	}
+	tree.Delete(3)
+	tree.Update(4, 0)
+	if err := checkVersion(snapshot, model); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.root.getHeight() != tree.Root().Height() {
+		t.Errorf("the snapshot has height %d, want the tree's %d", snapshot.root.getHeight(), tree.Root().Height())
+	}
+	if next, _ := snapshot.Delete(5); !tree.Search(5) || next.Search(5) {
+		t.Errorf("a new version of the snapshot changed the tree")
+	}
+}
+
+func TestPersistent_ConcurrentReaders(t *testing.T) {
+	const versions, readers = 2000, 4
+	// the writer publishes version k, holding the keys 0 to k-1, each mapped to itself;
+	// readers load whatever version is current and check that it is complete
+	var current atomic.Pointer[Persistent]
+	current.Store(NewPersistentWithIntComparator())
+	var wg sync.WaitGroup
+	var done atomic.Bool
+	for g := 0; g < readers; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !done.Load() {
+				version := current.Load()
+				k := version.Size()
+				if k == 0 {
+					continue
+				}
+				if key, _, ok := version.Max(); !ok || key != k-1 {
+					t.Errorf("version %d: Max() = %v, %v", k, key, ok)
+					return
+				}
+				if value, err := version.ReturnNodeValue(k / 2); err != nil || value != k/2 {
+					t.Errorf("version %d: ReturnNodeValue(%d) = %v, %v", k, k/2, value, err)
+					return
+				}
+				if rank := version.Rank(k); rank != k {
+					t.Errorf("version %d: Rank(%d) = %d", k, k, rank)
+					return
+				}
+			}
+		}()
+	}
+	version := current.Load()
+	for k := 1; k <= versions; k++ {
+		version, _ = version.Insert(k-1, k-1)
+		current.Store(version)
+	}
+	done.Store(true)
+	wg.Wait()
+	if current.Load().Size() != versions {
+		t.Errorf("Size() = %d, want %d", current.Load().Size(), versions)
+	}
+}
+
+// BenchmarkPersistent_Insert inserts ascending keys into a version of n keys, keeping every version,
+// so its cost per op grows with log n.
+func BenchmarkPersistent_Insert(b *testing.B) {
+	for _, n := range []int{1000, 10000, 100000, 1000000} {
+		b.Run(fmt.Sprintf("n=%d", n), func(b *testing.B) {
+			keys := make([]interface{}, n)
+			for i := range keys {
+				keys[i] = i
+			}
+			tree, _ := FromSorted(utils.IntComparator, keys, nil)
+			version := tree.Snapshot()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				version, _ = version.Insert(n+i, nil)
+			}
+		})
+	}
+}
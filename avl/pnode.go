@@ -0,0 +1,201 @@
+package avl
+
+import (
+	"github.com/chancetudor/trees"
+	"github.com/emirpasic/gods/utils"
+)
+
+// pnode is a node of a path-copied AVL tree. Once a pnode is reachable from a published root, it never changes:
+// insertions and deletions copy the nodes on the path from the root to the change, and the rotations that
+// rebalance them copy too, so every other subtree is shared between the old root and the new one.
+// pnodes store no parent pointers, since a shared subtree has a different parent in every tree that holds it.
+type pnode struct {
+	left   *pnode
+	right  *pnode
+	key    interface{}
+	value  interface{}
+	height int // the height of the node's subtree: 1 for a leaf
+	size   int // number of nodes in the subtree rooted at this node
+}
+
+// newPNode returns a new pnode holding key and value with left and right as its children,
+// computing its height and size from theirs.
+func newPNode(key, value interface{}, left, right *pnode) *pnode {
+	return &pnode{
+		left:   left,
+		right:  right,
+		key:    key,
+		value:  value,
+		height: 1 + max(left.getHeight(), right.getHeight()),
+		size:   1 + left.subtreeSize() + right.subtreeSize(),
+	}
+}
+
+// getHeight returns the height stored in the node, or 0 if the node is nil.
+func (node *pnode) getHeight() int {
+	if node == nil {
+		return 0
+	}
+
+	return node.height
+}
+
+// subtreeSize returns the number of nodes in the subtree rooted at the node, or 0 if the node is nil.
+func (node *pnode) subtreeSize() int {
+	if node == nil {
+		return 0
+	}
+
+	return node.size
+}
+
+// balancePNode returns the root of a balanced subtree holding key and value between the subtrees left and right,
+// whose heights may differ by up to two, as they do after one insertion or deletion below a balanced node.
+// The rotations build new nodes instead of changing left or right.
+func balancePNode(key, value interface{}, left, right *pnode) *pnode {
+	switch lh, rh := left.getHeight(), right.getHeight(); {
+	case lh > rh+1:
+		if left.left.getHeight() < left.right.getHeight() { // left-right: the middle subtree is the tallest
+			middle := left.right
+			return newPNode(middle.key, middle.value,
+				newPNode(left.key, left.value, left.left, middle.left),
+				newPNode(key, value, middle.right, right))
+		}
+		return newPNode(left.key, left.value, left.left, newPNode(key, value, left.right, right))
+	case rh > lh+1:
+		if right.right.getHeight() < right.left.getHeight() { // right-left: the middle subtree is the tallest
+			middle := right.left
+			return newPNode(middle.key, middle.value,
+				newPNode(key, value, left, middle.left),
+				newPNode(right.key, right.value, middle.right, right.right))
+		}
+		return newPNode(right.key, right.value, newPNode(key, value, left, right.left), right.right)
+	default:
+		return newPNode(key, value, left, right)
+	}
+}
+
+// pinsert returns the root of a copy of the subtree rooted at node with a new entry for key and value,
+// or a DuplicateError if the key already exists. It copies O(log n) nodes.
+func pinsert(comparator utils.Comparator, node *pnode, key, value interface{}) (*pnode, error) {
+	if node == nil {
+		return newPNode(key, value, nil, nil), nil
+	}
+	switch compare := comparator(key, node.key); {
+	case compare < 0:
+		left, err := pinsert(comparator, node.left, key, value)
+		if err != nil {
+			return nil, err
+		}
+		return balancePNode(node.key, node.value, left, node.right), nil
+	case compare > 0:
+		right, err := pinsert(comparator, node.right, key, value)
+		if err != nil {
+			return nil, err
+		}
+		return balancePNode(node.key, node.value, node.left, right), nil
+	default:
+		return nil, NewDuplicateError(key)
+	}
+}
+
+// pupdate returns the root of a copy of the subtree rooted at node in which key maps to value,
+// or a NilNodeError if the key does not exist. The shape does not change, so no rebalancing is needed.
+func pupdate(comparator utils.Comparator, node *pnode, key, value interface{}) (*pnode, error) {
+	if node == nil {
+		return nil, NewNilNodeError(key)
+	}
+	switch compare := comparator(key, node.key); {
+	case compare < 0:
+		left, err := pupdate(comparator, node.left, key, value)
+		if err != nil {
+			return nil, err
+		}
+		return newPNode(node.key, node.value, left, node.right), nil
+	case compare > 0:
+		right, err := pupdate(comparator, node.right, key, value)
+		if err != nil {
+			return nil, err
+		}
+		return newPNode(node.key, node.value, node.left, right), nil
+	default:
+		return newPNode(node.key, value, node.left, node.right), nil
+	}
+}
+
+// pdelete returns the root of a copy of the subtree rooted at node without the entry for key,
+// and the node that held the entry, or a NilNodeError if the key does not exist.
+func pdelete(comparator utils.Comparator, node *pnode, key interface{}) (*pnode, *pnode, error) {
+	if node == nil {
+		return nil, nil, NewNilNodeError(key)
+	}
+	switch compare := comparator(key, node.key); {
+	case compare < 0:
+		left, removed, err := pdelete(comparator, node.left, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return balancePNode(node.key, node.value, left, node.right), removed, nil
+	case compare > 0:
+		right, removed, err := pdelete(comparator, node.right, key)
+		if err != nil {
+			return nil, nil, err
+		}
+		return balancePNode(node.key, node.value, node.left, right), removed, nil
+	case node.left == nil:
+		return node.right, node, nil
+	case node.right == nil:
+		return node.left, node, nil
+	default: // the node has two subtrees; its successor takes its place
+		right, successor := pdeleteMin(node.right)
+		return balancePNode(successor.key, successor.value, node.left, right), node, nil
+	}
+}
+
+// pdeleteMin returns the root of a copy of the subtree rooted at node without its smallest entry,
+// and the node that held that entry. The node must not be nil.
+func pdeleteMin(node *pnode) (*pnode, *pnode) {
+	if node.left == nil {
+		return node.right, node
+	}
+	left, smallest := pdeleteMin(node.left)
+
+	return balancePNode(node.key, node.value, left, node.right), smallest
+}
+
+// pfind returns the node holding key in the subtree rooted at node, or nil if the key does not exist.
+func pfind(comparator utils.Comparator, node *pnode, key interface{}) *pnode {
+	for node != nil {
+		switch compare := comparator(key, node.key); {
+		case compare < 0:
+			node = node.left
+		case compare > 0:
+			node = node.right
+		default:
+			return node
+		}
+	}
+
+	return nil
+}
+
+// prange calls fn, in ascending key order, for every entry of the subtree rooted at node
+// whose key lies between lo and hi, skipping the subtrees outside the range.
+// It returns false if fn returned false.
+func prange(comparator utils.Comparator, node *pnode, lo, hi trees.Bound, fn func(key, value interface{}) bool) bool {
+	if node == nil {
+		return true
+	}
+	aboveLo, belowHi := lo.LowerContains(comparator, node.key), hi.UpperContains(comparator, node.key)
+	if aboveLo && !prange(comparator, node.left, lo, hi, fn) {
+		return false
+	}
+	if aboveLo && belowHi && !fn(node.key, node.value) {
+		return false
+	}
+	if belowHi {
+		return prange(comparator, node.right, lo, hi, fn)
+	}
+
+	return true
+}
@@ -0,0 +1,84 @@
+package avl
+
+import (
+	"github.com/chancetudor/trees"
+)
+
+// RangeIterator walks, in ascending order, the entries of an AVL whose keys lie between two bounds.
+// Creating it costs O(log n) and each step O(1) amortized, so visiting k entries costs O(log n + k).
+// Inserting into or deleting from the tree invalidates the RangeIterator.
+type RangeIterator struct {
+	tree *AVL        // the tree being iterated
+	hi   trees.Bound // the upper bound of the range
+	next *Node       // the node Next moves onto, nil once the range is exhausted
+	node *Node       // the current node, nil before the first call to Next and after the last
+}
+
+// RangeIterator returns a pointer to a RangeIterator over the entries whose keys lie between lo and hi.
+// Each bound may be inclusive, exclusive, or unbounded; see package trees.
+// The iterator starts before the first entry in the range; call Next to move onto it.
+func (tree *AVL) RangeIterator(lo, hi trees.Bound) *RangeIterator {
+	return &RangeIterator{
+		tree: tree,
+		hi:   hi,
+		next: tree.lowerBoundNode(lo),
+		node: nil,
+	}
+}
+
+// Next moves the iterator onto the next entry in the range.
+// The function returns false once the range is exhausted.
+func (it *RangeIterator) Next() bool {
+	it.node = it.next
+	if it.node == nil || !it.hi.UpperContains(it.tree.comparator, it.node.key()) {
+		it.node, it.next = nil, nil
+		return false
+	}
+	it.next = it.node.successor()
+
+	return true
+}
+
+// Key returns the key of the current entry, or nil if the iterator is not on an entry.
+func (it *RangeIterator) Key() interface{} {
+	if it.node == nil {
+		return nil
+	}
+
+	return it.node.key()
+}
+
+// Value returns the value of the current entry, or nil if the iterator is not on an entry.
+func (it *RangeIterator) Value() interface{} {
+	if it.node == nil {
+		return nil
+	}
+
+	return it.node.value()
+}
+
+// Range calls fn, in ascending key order, for every entry whose key lies between lo and hi.
+// Each bound may be inclusive, exclusive, or unbounded; see package trees.
+// If fn returns false, Range stops early.
+// fn must not insert into or delete from the tree.
+func (tree *AVL) Range(lo, hi trees.Bound, fn func(key, value interface{}) bool) {
+	it := tree.RangeIterator(lo, hi)
+	for it.Next() {
+		if !fn(it.Key(), it.Value()) {
+			return
+		}
+	}
+}
+
+// lowerBoundNode returns the node with the smallest key within the lower bound lo,
+// or nil if no key is within it.
+func (tree *AVL) lowerBoundNode(lo trees.Bound) *Node {
+	switch lo.Kind {
+	case trees.Inclusive:
+		return tree.ceilingNode(lo.Key)
+	case trees.Exclusive:
+		return tree.higherNode(lo.Key)
+	default:
+		return tree.minNode()
+	}
+}
@@ -0,0 +1,165 @@
+package avl
+
+import (
+	"sync"
+)
+
+// parallelThreshold is the combined number of entries below which the Parallel set operations
+// stop spawning goroutines.
+const parallelThreshold = 4096
+
+// Union returns a tree holding every entry of a and of b, using a's comparator and aggregate.
+// When both trees hold a key, resolve receives the key, a's value, and b's value, and returns the value to keep;
+// if resolve is nil, b's value is kept.
+// Union splits a around the root of b and recurses on both halves, so it does O(m log(n/m + 1)) work
+// for trees of sizes m <= n, instead of m separate insertions.
+// Both a and b are left empty.
+func Union(a, b *AVL, resolve func(key, aValue, bValue interface{}) interface{}) *AVL {
+	return setOperation(a, b, func(scratch *AVL) *Node {
+		return scratch.union(a.Root(), b.Root(), resolve, false)
+	})
+}
+
+// ParallelUnion is like Union, except that it processes large disjoint subtrees in separate goroutines.
+// resolve may be called concurrently.
+func ParallelUnion(a, b *AVL, resolve func(key, aValue, bValue interface{}) interface{}) *AVL {
+	return setOperation(a, b, func(scratch *AVL) *Node {
+		return scratch.union(a.Root(), b.Root(), resolve, true)
+	})
+}
+
+// Intersection returns a tree holding the entries whose keys are in both a and b, using a's comparator and aggregate.
+// resolve receives each such key, a's value, and b's value, and returns the value to keep;
+// if resolve is nil, b's value is kept.
+// Like Union, it does O(m log(n/m + 1)) work.
+// Both a and b are left empty.
+func Intersection(a, b *AVL, resolve func(key, aValue, bValue interface{}) interface{}) *AVL {
+	return setOperation(a, b, func(scratch *AVL) *Node {
+		return scratch.intersection(a.Root(), b.Root(), resolve, false)
+	})
+}
+
+// ParallelIntersection is like Intersection, except that it processes large disjoint subtrees in separate goroutines.
+// resolve may be called concurrently.
+func ParallelIntersection(a, b *AVL, resolve func(key, aValue, bValue interface{}) interface{}) *AVL {
+	return setOperation(a, b, func(scratch *AVL) *Node {
+		return scratch.intersection(a.Root(), b.Root(), resolve, true)
+	})
+}
+
+// Difference returns a tree holding the entries of a whose keys are not in b, using a's comparator and aggregate.
+// No value ever comes from b, so there are no conflicts to resolve.
+// Like Union, it does O(m log(n/m + 1)) work.
+// Both a and b are left empty.
+func Difference(a, b *AVL) *AVL {
+	return setOperation(a, b, func(scratch *AVL) *Node {
+		return scratch.difference(a.Root(), b.Root(), false)
+	})
+}
+
+// ParallelDifference is like Difference, except that it processes large disjoint subtrees in separate goroutines.
+func ParallelDifference(a, b *AVL) *AVL {
+	return setOperation(a, b, func(scratch *AVL) *Node {
+		return scratch.difference(a.Root(), b.Root(), true)
+	})
+}
+
+// setOperation runs op on a scratch tree configured like a, wraps the root it returns in a new tree,
+// and empties a and b.
+func setOperation(a, b *AVL, op func(scratch *AVL) *Node) *AVL {
+	result := a.emptyCopy()
+	root := op(a.emptyCopy())
+	result.setRoot(root)
+	result.setSize(root.subtreeSize())
+	a.Clear()
+	b.Clear()
+
+	return result
+}
+
+// union merges the subtrees rooted at t1 and t2 and returns the new root.
+func (tree *AVL) union(t1, t2 *Node, resolve func(key, aValue, bValue interface{}) interface{}, parallel bool) *Node {
+	if t1 == nil {
+		return t2
+	}
+	if t2 == nil {
+		return t1
+	}
+	size := t1.subtreeSize() + t2.subtreeSize()
+	mid, left2, right2 := tree.detach(t2)
+	left1, match, right1 := tree.splitAt(t1, mid.key())
+	if match != nil && resolve != nil {
+		mid.setValue(resolve(mid.key(), match.value(), mid.value()))
+	}
+	left, right := tree.recurse(size, parallel,
+		func(scratch *AVL) *Node { return scratch.union(left1, left2, resolve, parallel) },
+		func(scratch *AVL) *Node { return scratch.union(right1, right2, resolve, parallel) })
+
+	return tree.join(left, mid, right)
+}
+
+// intersection keeps the nodes of t2 whose keys are also under t1 and returns the new root.
+func (tree *AVL) intersection(t1, t2 *Node, resolve func(key, aValue, bValue interface{}) interface{}, parallel bool) *Node {
+	if t1 == nil || t2 == nil {
+		return nil
+	}
+	size := t1.subtreeSize() + t2.subtreeSize()
+	mid, left2, right2 := tree.detach(t2)
+	left1, match, right1 := tree.splitAt(t1, mid.key())
+	left, right := tree.recurse(size, parallel,
+		func(scratch *AVL) *Node { return scratch.intersection(left1, left2, resolve, parallel) },
+		func(scratch *AVL) *Node { return scratch.intersection(right1, right2, resolve, parallel) })
+	if match == nil {
+		return tree.join2(left, right)
+	}
+	if resolve != nil {
+		mid.setValue(resolve(mid.key(), match.value(), mid.value()))
+	}
+
+	return tree.join(left, mid, right)
+}
+
+// difference removes from t1 the keys under t2 and returns the new root.
+func (tree *AVL) difference(t1, t2 *Node, parallel bool) *Node {
+	if t1 == nil || t2 == nil {
+		return t1
+	}
+	size := t1.subtreeSize() + t2.subtreeSize()
+	mid, left2, right2 := tree.detach(t2)
+	left1, _, right1 := tree.splitAt(t1, mid.key())
+	left, right := tree.recurse(size, parallel,
+		func(scratch *AVL) *Node { return scratch.difference(left1, left2, parallel) },
+		func(scratch *AVL) *Node { return scratch.difference(right1, right2, parallel) })
+
+	return tree.join2(left, right)
+}
+
+// detach severs a subtree root from its children and returns the root and its former children.
+func (tree *AVL) detach(node *Node) (*Node, *Node, *Node) {
+	left, right := node.leftChild(), node.rightChild()
+	left.setParent(nil)
+	right.setParent(nil)
+	node.clear()
+
+	return node, left, right
+}
+
+// recurse runs the two halves of a set operation and returns their results.
+// If parallel is set and the operation spans at least parallelThreshold entries,
+// the left half runs in its own goroutine with its own scratch tree.
+func (tree *AVL) recurse(size int, parallel bool, left, right func(scratch *AVL) *Node) (*Node, *Node) {
+	if !parallel || size < parallelThreshold {
+		return left(tree), right(tree)
+	}
+	var leftRoot *Node
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		leftRoot = left(tree.emptyCopy())
+	}()
+	rightRoot := right(tree)
+	wg.Wait()
+
+	return leftRoot, rightRoot
+}
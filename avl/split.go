@@ -0,0 +1,157 @@
+package avl
+
+// Split divides the tree around key and returns two new trees:
+// the first holds every entry whose key is smaller than key, and the second every other entry.
+// The tree Split is called on is left empty.
+// Split rebuilds the two halves by joining subtrees along the search path for key,
+// using the heights the nodes already store, so it runs in O(log n).
+func (tree *AVL) Split(key interface{}) (*AVL, *AVL) {
+	lessRoot, restRoot := tree.split(tree.Root(), key)
+	less, rest := tree.emptyCopy(), tree.emptyCopy()
+	less.setRoot(lessRoot)
+	less.setSize(lessRoot.subtreeSize())
+	rest.setRoot(restRoot)
+	rest.setSize(restRoot.subtreeSize())
+	tree.Clear()
+
+	return less, rest
+}
+
+// Join concatenates two trees and returns the result, which uses left's comparator and aggregate.
+// Every key of left must be smaller than every key of right; otherwise Join returns an OverlapError
+// and leaves both trees untouched.
+// On success, left and right are left empty.
+// Join links the shorter tree into the spine of the taller one, so it runs in O(log n).
+func Join(left, right *AVL) (*AVL, error) {
+	if !left.IsEmpty() && !right.IsEmpty() &&
+		left.comparator(left.maxNode().key(), right.minNode().key()) >= 0 {
+		return nil, NewOverlapError(right.minNode().key())
+	}
+	joined := left.emptyCopy()
+	joined.setRoot(joined.join2(left.Root(), right.Root()))
+	joined.setSize(left.Size() + right.Size())
+	left.Clear()
+	right.Clear()
+
+	return joined, nil
+}
+
+// emptyCopy returns a pointer to an empty AVL with the same configuration as the tree.
+func (tree *AVL) emptyCopy() *AVL {
+	return &AVL{
+		root:       nil,
+		comparator: tree.comparator,
+		size:       0,
+		aggregate:  tree.aggregate,
+		keyType:    tree.keyType,
+		valueType:  tree.valueType,
+		keyCodec:   tree.keyCodec,
+		valueCodec: tree.valueCodec,
+	}
+}
+
+// split divides the subtree rooted at node into the nodes whose keys are smaller than key and the rest,
+// returning the roots of the two rebuilt subtrees.
+// The tree's root is used as scratch space.
+func (tree *AVL) split(node *Node, key interface{}) (*Node, *Node) {
+	if node == nil {
+		return nil, nil
+	}
+	left, right := node.leftChild(), node.rightChild()
+	left.setParent(nil)
+	right.setParent(nil)
+	node.clear()
+	if tree.comparator(node.key(), key) < 0 { // node and its left subtree are smaller than key
+		less, rest := tree.split(right, key)
+		return tree.join(left, node, less), rest
+	}
+	less, rest := tree.split(left, key)
+
+	return less, tree.join(rest, node, right)
+}
+
+// splitAt divides the subtree rooted at node into the nodes whose keys are smaller than key,
+// the node whose key equals key, if any, and the nodes whose keys are greater than key.
+// It returns the roots of the two rebuilt subtrees and the detached matching node, or nil.
+// The tree's root is used as scratch space.
+func (tree *AVL) splitAt(node *Node, key interface{}) (*Node, *Node, *Node) {
+	if node == nil {
+		return nil, nil, nil
+	}
+	left, right := node.leftChild(), node.rightChild()
+	left.setParent(nil)
+	right.setParent(nil)
+	node.clear()
+	compare := tree.comparator(node.key(), key)
+	switch {
+	case compare < 0:
+		less, match, greater := tree.splitAt(right, key)
+		return tree.join(left, node, less), match, greater
+	case compare > 0:
+		less, match, greater := tree.splitAt(left, key)
+		return less, match, tree.join(greater, node, right)
+	default:
+		return left, node, right
+	}
+}
+
+// join links the subtrees rooted at left and right under mid and rebalances, returning the new root.
+// Every key under left must be smaller than mid's key, which must be smaller than every key under right.
+// The tree's root is used as scratch space.
+func (tree *AVL) join(left, mid, right *Node) *Node {
+	var parent *Node
+	switch {
+	case left.getHeight() > right.getHeight()+1:
+		// descend the right spine of left to the first subtree no more than one taller than right
+		tree.setRoot(left)
+		spine := left
+		for spine.getHeight() > right.getHeight()+1 {
+			parent = spine
+			spine = spine.rightChild()
+		}
+		parent.setRightChild(mid)
+		tree.link(mid, spine, right)
+	case right.getHeight() > left.getHeight()+1:
+		// descend the left spine of right to the first subtree no more than one taller than left
+		tree.setRoot(right)
+		spine := right
+		for spine.getHeight() > left.getHeight()+1 {
+			parent = spine
+			spine = spine.leftChild()
+		}
+		parent.setLeftChild(mid)
+		tree.link(mid, left, spine)
+	default:
+		tree.setRoot(mid)
+		tree.link(mid, left, right)
+	}
+	mid.setParent(parent)
+	tree.fixup(mid)
+
+	return tree.Root()
+}
+
+// link sets left and right as mid's children.
+func (tree *AVL) link(mid, left, right *Node) {
+	mid.setLeftChild(left)
+	left.setParent(mid)
+	mid.setRightChild(right)
+	right.setParent(mid)
+}
+
+// join2 concatenates the subtrees rooted at left and right, returning the new root.
+// Every key under left must be smaller than every key under right.
+// The smallest node of right is removed and used as the middle node of a join.
+func (tree *AVL) join2(left, right *Node) *Node {
+	if left == nil {
+		return right
+	}
+	if right == nil {
+		return left
+	}
+	tree.setRoot(right)
+	mid := right.subtreeMin()
+	tree.deleteNode(mid)
+
+	return tree.join(left, mid, tree.Root())
+}
@@ -0,0 +1,110 @@
+package avl
+
+import (
+	"github.com/chancetudor/trees"
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"testing"
+)
+
+// checkTree fails the test if the tree breaks its balance invariants or stores wrong sizes,
+// or if its keys are not exactly want, in order.
+func checkTree(t *testing.T, tree *AVL, want []int) {
+	t.Helper()
+	checkAVL(t, tree.Root())
+	checkSizes(t, tree.Root())
+	if tree.Size() != len(want) {
+		t.Fatalf("Size() = %d, want %d", tree.Size(), len(want))
+	}
+	it := tree.Iterator()
+	for _, key := range want {
+		if !it.Next() || it.Key() != key {
+			t.Fatalf("iterated to %v, want %d", it.Key(), key)
+		}
+	}
+	if it.Next() {
+		t.Fatalf("unexpected key %v after %v", it.Key(), want)
+	}
+}
+
+// sequence returns the ints in [from, to).
+func sequence(from, to int) []int {
+	ints := make([]int, 0, to-from)
+	for i := from; i < to; i++ {
+		ints = append(ints, i)
+	}
+
+	return ints
+}
+
+func TestAVL_Split(t *testing.T) {
+	for _, pivot := range []int{-1, 0, 1, 137, 250, 499, 500, 1000} {
+		tree := NewWithIntComparator()
+		for _, key := range rand.New(rand.NewSource(int64(pivot))).Perm(500) {
+			tree.Insert(key, key)
+		}
+		less, rest := tree.Split(pivot)
+		split := min(max(pivot, 0), 500)
+		checkTree(t, less, sequence(0, split))
+		checkTree(t, rest, sequence(split, 500))
+		if !tree.IsEmpty() || tree.Root() != nil {
+			t.Errorf("Split(%d) left %d entries in the original tree", pivot, tree.Size())
+		}
+		// the halves are ordinary trees
+		less.Insert(-5, -5)
+		rest.Delete(split)
+	}
+}
+
+func TestAVL_Join(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, sizes := range [][2]int{{0, 0}, {0, 10}, {10, 0}, {1, 1}, {3, 700}, {700, 3}, {300, 300}, {1, 1000}} {
+		left, right := NewWithIntComparator(), NewWithIntComparator()
+		for _, key := range r.Perm(sizes[0]) {
+			left.Insert(key, key)
+		}
+		for _, key := range r.Perm(sizes[1]) {
+			right.Insert(sizes[0]+key, key)
+		}
+		joined, err := Join(left, right)
+		if err != nil {
+			t.Fatalf("Join() of sizes %v error = %v", sizes, err)
+		}
+		checkTree(t, joined, sequence(0, sizes[0]+sizes[1]))
+		if !left.IsEmpty() || !right.IsEmpty() {
+			t.Errorf("Join() left its inputs non-empty")
+		}
+	}
+}
+
+func TestAVL_JoinOverlap(t *testing.T) {
+	left, right := NewWithIntComparator(), NewWithIntComparator()
+	for key := 0; key < 10; key++ {
+		left.Insert(key, key)
+		right.Insert(key+9, key)
+	}
+	if _, err := Join(left, right); err == nil {
+		t.Fatalf("Join() of overlapping trees returned no error")
+	}
+	if left.Size() != 10 || right.Size() != 10 {
+		t.Errorf("failed Join() changed its inputs")
+	}
+}
+
+func TestAVL_SplitJoinKeepsAggregates(t *testing.T) {
+	tree := NewWithAggregate(utils.IntComparator, sumOfValues)
+	for _, key := range rand.New(rand.NewSource(3)).Perm(300) {
+		tree.Insert(key, key)
+	}
+	less, rest := tree.Split(100)
+	if got := less.AggregateRange(trees.Bound{}, trees.Bound{}); got != 99*100/2 {
+		t.Errorf("sum of lower half = %v, want %d", got, 99*100/2)
+	}
+	if got := rest.AggregateRange(trees.Including(100), trees.Excluding(110)); got != 1045 {
+		t.Errorf("sum of [100, 110) in upper half = %v, want 1045", got)
+	}
+	joined, _ := Join(less, rest)
+	if got := joined.AggregateRange(trees.Including(95), trees.Including(104)); got != 995 {
+		t.Errorf("sum of [95, 104] after Join = %v, want 995", got)
+	}
+}
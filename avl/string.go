@@ -0,0 +1,73 @@
+package avl
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/internal/render"
+	"github.com/emirpasic/gods/utils"
+	"io"
+	"strings"
+)
+
+// String returns the structure of the tree drawn sideways with box-drawing characters with every node's balance factor.
+// It is the same as Render with the Annotate option, and what the %+v and %s verbs print.
+func (tree *AVL) String() string {
+	return tree.Render(trees.RenderOptions{Annotate: true})
+}
+
+// Render returns the structure of the tree drawn with box-drawing characters, one line per row,
+// laid out and labeled as opts says; see trees.RenderOptions.
+// An empty tree is drawn as "Empty tree: []".
+func (tree *AVL) Render(opts trees.RenderOptions) string {
+	if tree.IsEmpty() {
+		return "Empty tree: []\n"
+	}
+	nodes := make(map[*Node]*render.Node, tree.Size())
+	tree.walk(trees.PreOrder, func(node *Node) bool {
+		label := utils.ToString(node.key())
+		if opts.Label != nil {
+			label = opts.Label(node.key(), node.value())
+		}
+		if opts.Annotate {
+			label += fmt.Sprintf("(%+d)", node.BalanceFactor())
+		}
+		nodes[node] = &render.Node{Label: label}
+		if parent := node.getParent(); parent != nil {
+			if node == parent.leftChild() {
+				nodes[parent].Left = nodes[node]
+			} else {
+				nodes[parent].Right = nodes[node]
+			}
+		}
+		return true
+	})
+	if opts.Layout == trees.TopDown {
+		return render.TopDown(nodes[tree.Root()])
+	}
+
+	return render.Sideways(nodes[tree.Root()])
+}
+
+// Format implements fmt.Formatter.
+// The %v verb prints the entries as a compact map in ascending key order, such as map[1:one 2:two],
+// while %+v and %s print the structure of the tree, as String does.
+func (tree *AVL) Format(f fmt.State, verb rune) {
+	switch {
+	case verb == 's', verb == 'v' && f.Flag('+'):
+		io.WriteString(f, tree.String())
+	case verb == 'v':
+		var b strings.Builder
+		b.WriteString("map[")
+		tree.walk(trees.InOrder, func(node *Node) bool {
+			if b.Len() > len("map[") {
+				b.WriteString(" ")
+			}
+			fmt.Fprintf(&b, "%v:%v", node.key(), node.value())
+			return true
+		})
+		b.WriteString("]")
+		io.WriteString(f, b.String())
+	default:
+		fmt.Fprintf(f, "%%!%c(*avl.AVL)", verb)
+	}
+}
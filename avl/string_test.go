@@ -0,0 +1,71 @@
+package avl
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/emirpasic/gods/utils"
+	"testing"
+)
+
+// newSmallTree returns the balanced tree 1, 2, 3, built from sorted keys so its shape is fixed,
+// with each key mapped to its spelling.
+func newSmallTree(t *testing.T) *AVL {
+	tree, err := FromSorted(utils.IntComparator, []interface{}{1, 2, 3}, []interface{}{"one", "two", "three"})
+	if err != nil {
+		t.Fatalf("FromSorted() error = %v", err)
+	}
+
+	return tree
+}
+
+func TestAVL_String(t *testing.T) {
+	tree := newSmallTree(t)
+	want := "" +
+		"│   ┌── 3(+0)\n" +
+		"└── 2(+0)\n" +
+		"    └── 1(+0)\n"
+	if got := tree.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+	want = "" +
+		" 2\n" +
+		"┌┴─┐\n" +
+		"1  3\n"
+	if got := tree.Render(trees.RenderOptions{Layout: trees.TopDown}); got != want {
+		t.Errorf("Render(TopDown) =\n%s\nwant\n%s", got, want)
+	}
+	label := func(key, value interface{}) string {
+		return fmt.Sprint(value)
+	}
+	want = "" +
+		"│   ┌── three\n" +
+		"└── two\n" +
+		"    └── one\n"
+	if got := tree.Render(trees.RenderOptions{Label: label}); got != want {
+		t.Errorf("Render(Label) =\n%s\nwant\n%s", got, want)
+	}
+	if got := NewWithIntComparator().String(); got != "Empty tree: []\n" {
+		t.Errorf("String() of an empty tree = %q", got)
+	}
+}
+
+func TestAVL_Format(t *testing.T) {
+	tree := newSmallTree(t)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "map[1:one 2:two 3:three]"},
+		{"%+v", tree.String()},
+		{"%s", tree.String()},
+		{"%d", "%!d(*avl.AVL)"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, tree); got != tt.want {
+			t.Errorf("Sprintf(%q) = %q, want %q", tt.format, got, tt.want)
+		}
+	}
+	if got := fmt.Sprint(NewWithIntComparator()); got != "map[]" {
+		t.Errorf("Sprint() of an empty tree = %q, want \"map[]\"", got)
+	}
+}
@@ -2,7 +2,10 @@ package avl
 
 import (
 	"fmt"
+	"github.com/chancetudor/trees"
 	"github.com/emirpasic/gods/utils"
+	"io"
+	"reflect"
 )
 
 /* Package avl implements an AVL tree in Go
@@ -22,6 +25,11 @@ type AVL struct {
 	root       *Node            // the root Node
 	comparator utils.Comparator // the key comparator
 	size       int              // number of nodes in the tree
+	keyType    reflect.Type     // the type UnmarshalJSON decodes keys into, or nil
+	valueType  reflect.Type     // the type UnmarshalJSON decodes values into, or nil
+	keyCodec   trees.Codec      // the Codec MarshalBinary encodes keys with, or nil
+	valueCodec trees.Codec      // the Codec MarshalBinary encodes values with, or nil
+	aggregate  trees.Aggregate  // the subtree aggregate stored in every node, or nil
 }
 
 // NewWith returns a pointer to a BST where root is nil, size is 0,
@@ -51,6 +59,16 @@ func NewWithStringComparator() *AVL {
 	return NewWith(utils.StringComparator)
 }
 
+// NewWithAggregate returns a pointer to an AVL like NewWith does,
+// except that every node also stores the aggregate of its subtree, which AggregateRange uses.
+// The library keeps the aggregates current through every insertion, deletion, update, and rotation.
+func NewWithAggregate(comparator utils.Comparator, aggregate trees.Aggregate) *AVL {
+	tree := NewWith(comparator)
+	tree.aggregate = aggregate
+
+	return tree
+}
+
 // Insert takes a key and a value of type interface, and inserts a new Node with that key and value.
 // The function inserts by key; that is, the key of the new node is
 // compared against current nodes to find the correct insertion point.
@@ -61,8 +79,8 @@ func (tree *AVL) Insert(key, value interface{}) (interface{}, error) {
 	// tree is empty, so we set the new node as the root and increase the size of the tree by 1.
 	if tree.IsEmpty() {
 		tree.setRoot(newNode)
+		tree.refresh(newNode)
 		tree.setSize(tree.Size() + 1)
-		newNode.setHeight(0)
 		return newNode.key(), nil
 	}
 
@@ -92,7 +110,6 @@ func (tree *AVL) Insert(key, value interface{}) (interface{}, error) {
 	case compare > 0:
 		parent.setRightChild(newNode)
 	}
-	newNode.setHeight(newNode.calculateHeight())
 	tree.fixup(newNode)
 	tree.setSize(tree.Size() + 1)
 
@@ -108,13 +125,21 @@ func (tree *AVL) Delete(key interface{}) (interface{}, error) {
 		return nil, err
 	}
 	nodeToDeleteKey := nodeToDelete.key()
+	tree.deleteNode(nodeToDelete)
 
+	return nodeToDeleteKey, nil
+}
+
+// deleteNode removes a node from the tree, rebalances the tree, and decrements the size of the tree.
+func (tree *AVL) deleteNode(nodeToDelete *Node) {
 	// node is already a leaf
 	if nodeToDelete.isLeaf() {
 		tree.pruneLeaf(nodeToDelete)
-		return nodeToDeleteKey, nil
+		tree.setSize(tree.Size() - 1)
+		return
 	}
 
+	fixFrom := nodeToDelete.getParent() // the lowest node whose subtree lost a node
 	switch {
 	case nodeToDelete.leftChild() == nil: // the node to delete only has a right subtree
 		tree.replaceSubTree(nodeToDelete, nodeToDelete.rightChild())
@@ -122,7 +147,9 @@ func (tree *AVL) Delete(key interface{}) (interface{}, error) {
 		tree.replaceSubTree(nodeToDelete, nodeToDelete.leftChild())
 	default: // the node to delete has two subtrees
 		successor := nodeToDelete.successor()
+		fixFrom = successor
 		if successor.getParent() != nodeToDelete {
+			fixFrom = successor.getParent()
 			tree.replaceSubTree(successor, successor.rightChild())
 			successor.setRightChild(nodeToDelete.rightChild())
 			successor.rightChild().setParent(successor)
@@ -130,24 +157,39 @@ func (tree *AVL) Delete(key interface{}) (interface{}, error) {
 		tree.replaceSubTree(nodeToDelete, successor)
 		successor.setLeftChild(nodeToDelete.leftChild())
 		successor.leftChild().setParent(successor)
+		// take over the old height of the deleted node's position. If the successor came from lower down,
+		// fixup compares against it on reaching this node to decide whether to stop; if the successor was the
+		// deleted node's right child, fixup starts here and recomputes the height without comparing, so it is unused.
+		successor.setHeight(nodeToDelete.getHeight())
 	}
-	tree.fixup(nodeToDelete)
+	nodeToDelete.clear()
+	tree.fixup(fixFrom)
 	tree.setSize(tree.Size() - 1)
-
-	return nodeToDeleteKey, nil
 }
 
-// fixup rebalances the AVL tree to maintain the invariant:
+// fixup walks from node up towards the root, refreshing each node's stored height
+// from its children's and rebalancing the AVL tree to maintain the invariant:
 // -1 <= getHeight(leftSubtree) - getHeight(rightSubtree) <= 1
+// Once a subtree ends up as tall as it was before, no node above it can have changed height
+// or become unbalanced, so the function only refreshes the sizes and aggregates on the rest of the path.
+// Node is the lowest node whose subtree changed; its stored height is not compared,
+// since it may be a node that just moved into its position.
 func (tree *AVL) fixup(node *Node) {
-	bf := node.BalanceFactor()
-	if bf < -1 || bf > 1 {
-		tree.rebalance(node)
-	}
-	if node.isRoot() {
-		return
+	for first := true; node != nil; first = false {
+		oldHeight := node.getHeight()
+		node.updateHeight()
+		tree.refresh(node)
+		bf := node.BalanceFactor()
+		if bf < -1 || bf > 1 {
+			tree.rebalance(node)
+			node = node.getParent() // node moved down; continue from the new root of its subtree
+		}
+		if !first && node.getHeight() == oldHeight {
+			tree.refreshPath(node.getParent())
+			return
+		}
+		node = node.getParent()
 	}
-	tree.fixup(node.getParent())
 }
 
 // replaceSubTree replaces the node to delete with a new root node of a subtree.
@@ -169,11 +211,11 @@ func (tree *AVL) replaceSubTree(toDelete *Node, replacementNode *Node) {
 }
 
 // pruneLeaf removes a leaf from the tree.
-// If the node to delete is the root, tree.Clear() is called.
+// If the node to delete is the root, the root is set to nil.
 // The function does not decrement the size of the tree.
 func (tree *AVL) pruneLeaf(toDelete *Node) {
 	if toDelete.isRoot() {
-		tree.Clear()
+		tree.setRoot(nil)
 		return
 	}
 	parent := toDelete.getParent()
@@ -183,8 +225,8 @@ func (tree *AVL) pruneLeaf(toDelete *Node) {
 	case toDelete == parent.rightChild(): // node to delete is right of parent
 		parent.setRightChild(nil)
 	}
-	tree.fixup(toDelete)
 	toDelete.clear()
+	tree.fixup(parent)
 }
 
 // rebalance determines which rotations to perform to maintain the AVL invariant.
@@ -243,8 +285,11 @@ func (tree *AVL) leftRotate(node *Node) {
 	}
 	newParent.setLeftChild(node)
 	node.setParent(newParent)
-	newParent.setHeight(newParent.calculateHeight())
-	node.setHeight(node.calculateHeight())
+	// node is now newParent's child, so its height must be current before newParent's
+	node.updateHeight()
+	newParent.updateHeight()
+	tree.refresh(node)
+	tree.refresh(newParent)
 }
 
 // rightRotate performs right rotations on the nodes
@@ -273,8 +318,11 @@ func (tree *AVL) rightRotate(node *Node) {
 	}
 	newParent.setRightChild(node)
 	node.setParent(newParent)
-	newParent.setHeight(newParent.calculateHeight())
-	node.setHeight(node.calculateHeight())
+	// node is now newParent's child, so its height must be current before newParent's
+	node.updateHeight()
+	newParent.updateHeight()
+	tree.refresh(node)
+	tree.refresh(newParent)
 }
 
 // Search takes a key and searches for the key in the tree.
@@ -302,25 +350,38 @@ func (tree *AVL) IsBalanced() bool {
 	}
 }
 
-// DepthFirstTraversal (pre-order traversal) traverses the binary search tree by printing the root node,
-// then recursively visiting the left and the right nodes of the current node.
-func (tree *AVL) DepthFirstTraversal() {
-	if !tree.IsEmpty() {
-		tree.Root().dfs()
-		return
-	}
+// DepthFirstTraversal (pre-order traversal) writes one line per node to w, visiting the root node first,
+// then the left and the right subtrees of each node. The root's line is preceded by a "ROOT" line.
+// The function returns the first error w returned, if there was one.
+func (tree *AVL) DepthFirstTraversal(w io.Writer) error {
+	return tree.print(w, trees.PreOrder)
+}
 
-	fmt.Println("Empty tree: []")
+// InOrderTraversal writes one line per node to w, in order from smallest to greatest key.
+// The root's line is preceded by a "ROOT" line.
+// The function returns the first error w returned, if there was one.
+func (tree *AVL) InOrderTraversal(w io.Writer) error {
+	return tree.print(w, trees.InOrder)
 }
 
-// InOrderTraversal prints every node's value in order from smallest to greatest.
-func (tree *AVL) InOrderTraversal() {
-	if !tree.IsEmpty() {
-		tree.Root().inOrder()
-		return
+// print writes every node's description to w in the given order, stopping at the first write error.
+func (tree *AVL) print(w io.Writer, order trees.TraversalOrder) error {
+	if tree.IsEmpty() {
+		_, err := fmt.Fprintln(w, "Empty tree: []")
+		return err
 	}
+	var err error
+	tree.walk(order, func(node *Node) bool {
+		if node.isRoot() {
+			if _, err = fmt.Fprintln(w, "ROOT"); err != nil {
+				return false
+			}
+		}
+		_, err = fmt.Fprintln(w, node.describe())
+		return err == nil
+	})
 
-	fmt.Println("Empty tree: []")
+	return err
 }
 
 // findNode takes a key and returns the node associated with that key.
@@ -342,6 +403,100 @@ func (tree *AVL) findNode(key interface{}) (*Node, error) {
 	return nil, NewNilNodeError(key)
 }
 
+// ceilingNode takes a key and returns the node with the smallest key greater than or equal to it.
+// Returns nil if no such node exists.
+func (tree *AVL) ceilingNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		compare := tree.comparator(key, tempNode.key())
+		switch {
+		case compare < 0:
+			candidate = tempNode
+			tempNode = tempNode.leftChild()
+		case compare > 0:
+			tempNode = tempNode.rightChild()
+		case compare == 0:
+			return tempNode
+		}
+	}
+
+	return candidate
+}
+
+// floorNode takes a key and returns the node with the largest key less than or equal to it.
+// Returns nil if no such node exists.
+func (tree *AVL) floorNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		compare := tree.comparator(key, tempNode.key())
+		switch {
+		case compare < 0:
+			tempNode = tempNode.leftChild()
+		case compare > 0:
+			candidate = tempNode
+			tempNode = tempNode.rightChild()
+		case compare == 0:
+			return tempNode
+		}
+	}
+
+	return candidate
+}
+
+// lowerNode takes a key and returns the node with the largest key strictly less than it.
+// Returns nil if no such node exists.
+func (tree *AVL) lowerNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		if tree.comparator(key, tempNode.key()) > 0 {
+			candidate = tempNode
+			tempNode = tempNode.rightChild()
+		} else {
+			tempNode = tempNode.leftChild()
+		}
+	}
+
+	return candidate
+}
+
+// higherNode takes a key and returns the node with the smallest key strictly greater than it.
+// Returns nil if no such node exists.
+func (tree *AVL) higherNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		if tree.comparator(key, tempNode.key()) < 0 {
+			candidate = tempNode
+			tempNode = tempNode.leftChild()
+		} else {
+			tempNode = tempNode.rightChild()
+		}
+	}
+
+	return candidate
+}
+
+// minNode returns the node with the smallest key in the tree, or nil if the tree is empty.
+func (tree *AVL) minNode() *Node {
+	if tree.IsEmpty() {
+		return nil
+	}
+
+	return tree.Root().subtreeMin()
+}
+
+// maxNode returns the node with the largest key in the tree, or nil if the tree is empty.
+func (tree *AVL) maxNode() *Node {
+	if tree.IsEmpty() {
+		return nil
+	}
+
+	return tree.Root().subtreeMax()
+}
+
 // Update takes a key and a value and updates a node with the existing key with the new value.
 // Returns the new value of the node or an error, if there was one.
 func (tree *AVL) Update(key interface{}, value interface{}) (interface{}, error) {
@@ -350,6 +505,9 @@ func (tree *AVL) Update(key interface{}, value interface{}) (interface{}, error)
 		return nil, err
 	}
 	matchingNode.setValue(value)
+	if tree.aggregate != nil {
+		tree.refreshPath(matchingNode)
+	}
 
 	return matchingNode.value(), nil
 }
@@ -363,6 +521,117 @@ func (tree *AVL) ReturnNodeValue(key interface{}) (interface{}, error) {
 	return matchingNode.value(), nil
 }
 
+// Floor takes a key and returns the entry with the largest key less than or equal to it.
+// The boolean is false if no such entry exists.
+func (tree *AVL) Floor(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.floorNode(key))
+}
+
+// Ceiling takes a key and returns the entry with the smallest key greater than or equal to it.
+// The boolean is false if no such entry exists.
+func (tree *AVL) Ceiling(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.ceilingNode(key))
+}
+
+// Lower takes a key and returns the entry with the largest key strictly less than it.
+// The boolean is false if no such entry exists.
+func (tree *AVL) Lower(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.lowerNode(key))
+}
+
+// Higher takes a key and returns the entry with the smallest key strictly greater than it.
+// The boolean is false if no such entry exists.
+func (tree *AVL) Higher(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.higherNode(key))
+}
+
+// Min returns the entry with the smallest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *AVL) Min() (interface{}, interface{}, bool) {
+	return entryOf(tree.minNode())
+}
+
+// Max returns the entry with the largest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *AVL) Max() (interface{}, interface{}, bool) {
+	return entryOf(tree.maxNode())
+}
+
+// PopMin removes and returns the entry with the smallest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *AVL) PopMin() (interface{}, interface{}, bool) {
+	return tree.pop(tree.minNode())
+}
+
+// PopMax removes and returns the entry with the largest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *AVL) PopMax() (interface{}, interface{}, bool) {
+	return tree.pop(tree.maxNode())
+}
+
+// Select takes an index i and returns the entry with the i-th smallest key, counting from 0.
+// The boolean is false if i is negative or not less than the size of the tree.
+func (tree *AVL) Select(i int) (interface{}, interface{}, bool) {
+	if i < 0 || i >= tree.Size() {
+		return nil, nil, false
+	}
+	tempNode := tree.Root()
+	for tempNode != nil {
+		leftSize := tempNode.leftChild().subtreeSize()
+		switch {
+		case i < leftSize:
+			tempNode = tempNode.leftChild()
+		case i > leftSize:
+			i -= leftSize + 1
+			tempNode = tempNode.rightChild()
+		default:
+			return entryOf(tempNode)
+		}
+	}
+
+	return nil, nil, false
+}
+
+// Rank takes a key and returns the number of keys in the tree that are smaller than it.
+// The key does not need to be in the tree.
+func (tree *AVL) Rank(key interface{}) int {
+	rank := 0
+	tempNode := tree.Root()
+	for tempNode != nil {
+		compare := tree.comparator(key, tempNode.key())
+		switch {
+		case compare < 0:
+			tempNode = tempNode.leftChild()
+		case compare > 0:
+			rank += tempNode.leftChild().subtreeSize() + 1
+			tempNode = tempNode.rightChild()
+		default:
+			return rank + tempNode.leftChild().subtreeSize()
+		}
+	}
+
+	return rank
+}
+
+// pop removes a node from the tree and returns its entry, or nil, nil, and false if the node is nil.
+func (tree *AVL) pop(node *Node) (interface{}, interface{}, bool) {
+	key, value, found := entryOf(node)
+	if found {
+		tree.deleteNode(node)
+	}
+
+	return key, value, found
+}
+
+// entryOf returns the key and value of a node and true, or nil, nil, and false if the node is nil.
+func entryOf(node *Node) (interface{}, interface{}, bool) {
+	if node == nil {
+		return nil, nil, false
+	}
+
+	return node.key(), node.value(), true
+}
+
 // Clear sets the root node to nil and sets the size of the tree to 0.
 func (tree *AVL) Clear() {
 	tree.setRoot(nil)
@@ -393,3 +662,21 @@ func (tree *AVL) setSize(newSize int) {
 func (tree *AVL) IsEmpty() bool {
 	return tree.size == 0
 }
+
+// refresh recomputes the subtree size of a node and, if the tree has an Aggregate,
+// the subtree aggregate of the node, from the values stored in its children.
+func (tree *AVL) refresh(node *Node) {
+	node.updateSize()
+	if tree.aggregate != nil {
+		node.agg = tree.aggregate.Combine(
+			tree.aggregate.Combine(tree.subtreeAggregate(node.leftChild()), tree.lift(node)),
+			tree.subtreeAggregate(node.rightChild()))
+	}
+}
+
+// refreshPath refreshes a node and every ancestor up to the root.
+func (tree *AVL) refreshPath(node *Node) {
+	for temp := node; temp != nil; temp = temp.getParent() {
+		tree.refresh(temp)
+	}
+}
@@ -0,0 +1,150 @@
+package avl
+
+import (
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// hasViolation reports whether err is a *ValidationError listing a violation of rule by key.
+func hasViolation(err error, key interface{}, rule Rule) bool {
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		return false
+	}
+	for _, v := range validationErr.Violations {
+		if v.Key == key && v.Rule == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newFifteen returns the perfectly balanced tree of the keys 0 to 14.
+func newFifteen(t *testing.T) *AVL {
+	keys := make([]interface{}, 15)
+	for i := range keys {
+		keys[i] = i
+	}
+	tree, err := FromSorted(utils.IntComparator, keys, nil)
+	if err != nil {
+		t.Fatalf("FromSorted() error = %v", err)
+	}
+
+	return tree
+}
+
+func TestAVL_Validate(t *testing.T) {
+	tree := NewWithIntComparator()
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("Validate() of an empty tree error = %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		key := rng.Intn(500)
+		if tree.Search(key) {
+			tree.Delete(key)
+		} else {
+			tree.Insert(key, i)
+		}
+		if i%100 == 0 {
+			if err := tree.Validate(); err != nil {
+				t.Fatalf("Validate() after %d operations error = %v", i, err)
+			}
+		}
+	}
+}
+
+func TestAVL_ValidateViolations(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(tree *AVL) *Node // breaks the tree and returns the node to blame, or nil
+		rule    Rule
+	}{
+		{"order", func(tree *AVL) *Node {
+			node := tree.Root().leftChild()
+			node.setKey(1000)
+			// the walk meets 1000 first, so the key after it is out of order
+			return node.successor()
+		}, RuleOrder},
+		{"parent", func(tree *AVL) *Node {
+			node := tree.Root().rightChild().leftChild()
+			node.setParent(tree.Root())
+			return node
+		}, RuleParent},
+		{"structure", func(tree *AVL) *Node {
+			leaf := tree.minNode()
+			leaf.setLeftChild(tree.Root())
+			return tree.Root()
+		}, RuleStructure},
+		{"size", func(tree *AVL) *Node {
+			tree.setSize(tree.Size() + 1)
+			return nil
+		}, RuleSize},
+		{"height", func(tree *AVL) *Node {
+			tree.Root().setHeight(tree.Root().getHeight() + 1)
+			return tree.Root()
+		}, RuleHeight},
+		{"subtree size", func(tree *AVL) *Node {
+			node := tree.Root().rightChild()
+			node.size++
+			return node
+		}, RuleSubtreeSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newFifteen(t)
+			var key interface{}
+			if node := tt.corrupt(tree); node != nil {
+				key = node.key()
+			}
+			err := tree.Validate()
+			if !hasViolation(err, key, tt.rule) {
+				t.Fatalf("Validate() = %v, want a %s violation at %v", err, tt.rule, key)
+			}
+			if !strings.Contains(err.Error(), string(tt.rule)) {
+				t.Errorf("Error() = %q does not name %s", err.Error(), tt.rule)
+			}
+		})
+	}
+}
+
+func TestAVL_ValidateBalance(t *testing.T) {
+	// a right-leaning chain with correct heights and sizes breaks only the balance rule
+	tree := NewWithIntComparator()
+	nodes := []*Node{NewNode(1, nil), NewNode(2, nil), NewNode(3, nil)}
+	nodes[0].setRightChild(nodes[1])
+	nodes[1].setParent(nodes[0])
+	nodes[1].setRightChild(nodes[2])
+	nodes[2].setParent(nodes[1])
+	for i := 2; i >= 0; i-- {
+		nodes[i].updateHeight()
+		tree.refresh(nodes[i])
+	}
+	tree.setRoot(nodes[0])
+	tree.setSize(3)
+	err := tree.Validate()
+	if !hasViolation(err, 1, RuleBalance) || len(err.(*ValidationError).Violations) != 1 {
+		t.Errorf("Validate() = %v, want only a balance violation at 1", err)
+	}
+}
+
+func TestAVL_ValidateAggregate(t *testing.T) {
+	keys := make([]interface{}, 15)
+	for i := range keys {
+		keys[i] = i
+	}
+	tree := NewWithAggregate(utils.IntComparator, sumOfValues)
+	if err := tree.loadSorted(keys, keys); err != nil {
+		t.Fatalf("loadSorted() error = %v", err)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	tree.Root().leftChild().agg = -1
+	if err := tree.Validate(); !hasViolation(err, tree.Root().leftChild().key(), RuleAggregate) {
+		t.Errorf("Validate() = %v, want an aggregate violation", err)
+	}
+}
@@ -0,0 +1,55 @@
+package trees
+
+import (
+	"github.com/emirpasic/gods/utils"
+)
+
+// BoundKind says whether a Bound includes its key, excludes it, or does not limit the range at all.
+type BoundKind int
+
+const (
+	Unbounded BoundKind = iota // no limit; the zero value
+	Inclusive                  // the bound's key is part of the range
+	Exclusive                  // the bound's key is not part of the range
+)
+
+// Bound is one end of a key range, used by the Range methods of bst.BST, avl.AVL, and rbt.RBT.
+// The zero Bound is unbounded.
+type Bound struct {
+	Key  interface{}
+	Kind BoundKind
+}
+
+// Including returns a Bound that includes key.
+func Including(key interface{}) Bound {
+	return Bound{Key: key, Kind: Inclusive}
+}
+
+// Excluding returns a Bound that excludes key.
+func Excluding(key interface{}) Bound {
+	return Bound{Key: key, Kind: Exclusive}
+}
+
+// LowerContains reports whether key lies within the range when b is used as its lower bound.
+func (b Bound) LowerContains(comparator utils.Comparator, key interface{}) bool {
+	switch b.Kind {
+	case Inclusive:
+		return comparator(key, b.Key) >= 0
+	case Exclusive:
+		return comparator(key, b.Key) > 0
+	default:
+		return true
+	}
+}
+
+// UpperContains reports whether key lies within the range when b is used as its upper bound.
+func (b Bound) UpperContains(comparator utils.Comparator, key interface{}) bool {
+	switch b.Kind {
+	case Inclusive:
+		return comparator(key, b.Key) <= 0
+	case Exclusive:
+		return comparator(key, b.Key) < 0
+	default:
+		return true
+	}
+}
@@ -0,0 +1,70 @@
+package bst
+
+import (
+	"fmt"
+	"github.com/emirpasic/gods/utils"
+)
+
+// FromSorted takes a comparator and keys in strictly ascending order, with their values,
+// and returns a pointer to a perfectly balanced BST holding them.
+// It runs in O(n); the only comparisons are the ones that check that the keys are sorted.
+// values may be nil, in which case every value is nil; otherwise it must be as long as keys.
+// The function returns a DuplicateError or an UnsortedError, and no tree, if the keys are not strictly ascending.
+func FromSorted(comparator utils.Comparator, keys, values []interface{}) (*BST, error) {
+	tree := NewWith(comparator)
+	if err := tree.loadSorted(keys, values); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// loadSorted replaces the contents of the tree with a perfectly balanced tree built from sorted keys and values.
+// The tree is left unchanged if the input is invalid.
+func (tree *BST) loadSorted(keys, values []interface{}) error {
+	if values != nil && len(values) != len(keys) {
+		return fmt.Errorf("LENGTH ERROR: %d keys but %d values", len(keys), len(values))
+	}
+	for i := 1; i < len(keys); i++ {
+		compare := tree.comparator(keys[i-1], keys[i])
+		switch {
+		case compare == 0:
+			return NewDuplicateError(keys[i])
+		case compare > 0:
+			return NewUnsortedError(keys[i])
+		}
+	}
+	tree.setRoot(tree.buildSorted(keys, values, 0, len(keys)))
+	tree.setSize(len(keys))
+
+	return nil
+}
+
+// buildSorted builds a subtree from keys[lo:hi] by making the middle key the root and recursing on each half,
+// and returns the subtree's root.
+func (tree *BST) buildSorted(keys, values []interface{}, lo, hi int) *Node {
+	if lo >= hi {
+		return nil
+	}
+	mid := lo + (hi-lo)/2
+	node := NewNode(keys[mid], valueAt(values, mid))
+	node.setLeftChild(tree.buildSorted(keys, values, lo, mid))
+	node.setRightChild(tree.buildSorted(keys, values, mid+1, hi))
+	if node.leftChild() != nil {
+		node.leftChild().setParent(node)
+	}
+	if node.rightChild() != nil {
+		node.rightChild().setParent(node)
+	}
+
+	return node
+}
+
+// valueAt returns values[i], or nil if values is nil.
+func valueAt(values []interface{}, i int) interface{} {
+	if values == nil {
+		return nil
+	}
+
+	return values[i]
+}
@@ -2,6 +2,7 @@ package bst
 
 import (
 	"fmt"
+	"strings"
 )
 
 type DuplicateError struct {
@@ -142,3 +143,60 @@ func (e *NilNodeError) Error() string {
 			"Please use Update() if you wish to update the value of the node.", e.Key)
 	}
 }
+
+type UnsortedError struct {
+	Key     interface{}
+	Message string
+}
+
+func NewUnsortedError(k interface{}) *UnsortedError {
+	return &UnsortedError{
+		Key:     k,
+		Message: "UNSORTED ERROR: ",
+	}
+}
+
+func (e *UnsortedError) Error() string {
+	return fmt.Sprintf(e.Message+"Key = %+v"+" is smaller than the key before it. "+
+		"Keys must be in strictly ascending order.", e.Key)
+}
+
+// Rule names an invariant that Validate checks.
+type Rule string
+
+const (
+	RuleOrder     Rule = "ORDER"     // an in-order walk meets the keys in strictly ascending order
+	RuleParent    Rule = "PARENT"    // every child points back at its parent, and the root has no parent
+	RuleStructure Rule = "STRUCTURE" // no node is reachable twice, so the nodes form a tree
+	RuleSize      Rule = "SIZE"      // the tree's size is its number of nodes
+)
+
+// Violation records one node that breaks one Rule.
+// Key is the offending node's key, or nil for a Rule about the whole tree.
+type Violation struct {
+	Key    interface{}
+	Rule   Rule
+	Detail string
+}
+
+type ValidationError struct {
+	Violations []Violation
+	Message    string
+}
+
+func NewValidationError(violations []Violation) *ValidationError {
+	return &ValidationError{
+		Violations: violations,
+		Message:    "VALIDATION ERROR: ",
+	}
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Message + fmt.Sprintf("%d violations", len(e.Violations)))
+	for _, v := range e.Violations {
+		b.WriteString(fmt.Sprintf("; Key = %+v breaks %s: %s", v.Key, v.Rule, v.Detail))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package bst
+
+import (
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/treetest"
+	"testing"
+)
+
+// newModelTree returns an empty BST as a trees.OrderedMap for the model-based tests.
+func newModelTree() trees.OrderedMap {
+	return NewWithIntComparator()
+}
+
+// validate checks every invariant of a BST after each operation of a model-based test.
+func validate(m trees.OrderedMap) error {
+	return m.(*BST).Validate()
+}
+
+func TestBST_Model(t *testing.T) {
+	treetest.TestModel(t, newModelTree, validate)
+}
+
+func FuzzBST(f *testing.F) {
+	treetest.Fuzz(f, newModelTree, validate)
+}
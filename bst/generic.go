@@ -0,0 +1,186 @@
+package bst
+
+import (
+	"cmp"
+)
+
+// Tree is a type-parameterized binary search tree mapping keys of type K to values of type V.
+// It offers the same operations as BST without boxing keys and values in interface{},
+// and keys are ordered by a func(a, b K) int instead of a gods comparator.
+// Duplicates are not allowed.
+type Tree[K, V any] struct {
+	root    *genericNode[K, V] // the root node
+	compare func(a, b K) int   // the key comparator
+	size    int                // number of nodes in the tree
+}
+
+// genericNode stores left, right, and parent node pointers, and the key and value of an entry in a Tree.
+type genericNode[K, V any] struct {
+	left   *genericNode[K, V]
+	right  *genericNode[K, V]
+	parent *genericNode[K, V]
+	key    K
+	value  V
+}
+
+// New returns a pointer to an empty Tree whose keys are ordered by cmp.Compare.
+func New[K cmp.Ordered, V any]() *Tree[K, V] {
+	return NewFunc[K, V](cmp.Compare[K])
+}
+
+// NewFunc returns a pointer to an empty Tree whose keys are ordered by compare.
+// compare must return a negative number when a < b, a positive number when a > b, and 0 when a == b.
+func NewFunc[K, V any](compare func(a, b K) int) *Tree[K, V] {
+	return &Tree[K, V]{
+		root:    nil,
+		compare: compare,
+		size:    0,
+	}
+}
+
+// Insert takes a key and a value and inserts a new node with that key and value.
+// The function returns the newly inserted node's key, or the zero key and an error, if there was one.
+func (tree *Tree[K, V]) Insert(key K, value V) (K, error) {
+	var parent *genericNode[K, V]
+	compare := 0
+	for tempNode := tree.root; tempNode != nil; {
+		parent = tempNode
+		compare = tree.compare(key, tempNode.key)
+		switch {
+		case compare < 0:
+			tempNode = tempNode.left
+		case compare > 0:
+			tempNode = tempNode.right
+		default:
+			var zero K
+			return zero, NewDuplicateError(key)
+		}
+	}
+
+	newNode := &genericNode[K, V]{key: key, value: value, parent: parent}
+	switch {
+	case parent == nil:
+		tree.root = newNode
+	case compare < 0:
+		parent.left = newNode
+	default:
+		parent.right = newNode
+	}
+	tree.size++
+
+	return key, nil
+}
+
+// Search takes a key and searches for the key in the tree.
+// The function returns a boolean, stating whether the key was found or not.
+func (tree *Tree[K, V]) Search(key K) bool {
+	return tree.findNode(key) != nil
+}
+
+// Update takes a key and a value and updates a node with the existing key with the new value.
+// Returns the new value of the node or an error, if there was one.
+func (tree *Tree[K, V]) Update(key K, value V) (V, error) {
+	matchingNode := tree.findNode(key)
+	if matchingNode == nil {
+		var zero V
+		return zero, NewNilNodeError(key)
+	}
+	matchingNode.value = value
+
+	return matchingNode.value, nil
+}
+
+// ReturnNodeValue takes a key and returns the value associated with the key or an error, if there was one.
+func (tree *Tree[K, V]) ReturnNodeValue(key K) (V, error) {
+	matchingNode := tree.findNode(key)
+	if matchingNode == nil {
+		var zero V
+		return zero, NewNilNodeError(key)
+	}
+
+	return matchingNode.value, nil
+}
+
+// Delete takes a key, removes the node from the tree, and decrements the size of the tree.
+// The function returns the key of the deleted node, or the zero key and an error, if there was one.
+func (tree *Tree[K, V]) Delete(key K) (K, error) {
+	nodeToDelete := tree.findNode(key)
+	if nodeToDelete == nil {
+		var zero K
+		return zero, NewNilNodeError(key)
+	}
+
+	switch {
+	case nodeToDelete.left == nil:
+		tree.replaceSubTree(nodeToDelete, nodeToDelete.right)
+	case nodeToDelete.right == nil:
+		tree.replaceSubTree(nodeToDelete, nodeToDelete.left)
+	default: // the node to delete has two subtrees
+		successor := nodeToDelete.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		if successor.parent != nodeToDelete {
+			tree.replaceSubTree(successor, successor.right)
+			successor.right = nodeToDelete.right
+			successor.right.parent = successor
+		}
+		tree.replaceSubTree(nodeToDelete, successor)
+		successor.left = nodeToDelete.left
+		successor.left.parent = successor
+	}
+	nodeToDelete.left, nodeToDelete.right, nodeToDelete.parent = nil, nil, nil
+	tree.size--
+
+	return nodeToDelete.key, nil
+}
+
+// Clear sets the root node to nil and sets the size of the tree to 0.
+func (tree *Tree[K, V]) Clear() {
+	tree.root = nil
+	tree.size = 0
+}
+
+// Size returns the size, or number of nodes in the tree, of the tree.
+func (tree *Tree[K, V]) Size() int {
+	return tree.size
+}
+
+// IsEmpty returns a boolean stating whether the tree is empty or not.
+func (tree *Tree[K, V]) IsEmpty() bool {
+	return tree.size == 0
+}
+
+// findNode takes a key and returns the node associated with that key, or nil if no node exists.
+func (tree *Tree[K, V]) findNode(key K) *genericNode[K, V] {
+	tempNode := tree.root
+	for tempNode != nil {
+		compare := tree.compare(key, tempNode.key)
+		switch {
+		case compare < 0:
+			tempNode = tempNode.left
+		case compare > 0:
+			tempNode = tempNode.right
+		default:
+			return tempNode
+		}
+	}
+
+	return nil
+}
+
+// replaceSubTree replaces the subtree rooted at toDelete with the subtree rooted at replacementNode.
+func (tree *Tree[K, V]) replaceSubTree(toDelete, replacementNode *genericNode[K, V]) {
+	parent := toDelete.parent
+	switch {
+	case parent == nil:
+		tree.root = replacementNode
+	case toDelete == parent.left:
+		parent.left = replacementNode
+	default:
+		parent.right = replacementNode
+	}
+	if replacementNode != nil {
+		replacementNode.parent = parent
+	}
+}
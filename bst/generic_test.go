@@ -0,0 +1,97 @@
+package bst
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// inOrderKeys returns the keys of a Tree from smallest to greatest.
+func inOrderKeys[K, V any](node *genericNode[K, V], keys []K) []K {
+	if node == nil {
+		return keys
+	}
+	keys = inOrderKeys(node.left, keys)
+	keys = append(keys, node.key)
+
+	return inOrderKeys(node.right, keys)
+}
+
+func TestTree_Random(t *testing.T) {
+	tree := New[int, string]()
+	model := make(map[int]string)
+	r := rand.New(rand.NewSource(1))
+	for step := 0; step < 20000; step++ {
+		key := r.Intn(500)
+		_, exists := model[key]
+		if r.Intn(2) == 0 {
+			_, err := tree.Insert(key, strings.Repeat("x", key%5))
+			if (err == nil) == exists {
+				t.Fatalf("step %d: Insert(%d) error = %v, key present = %v", step, key, err, exists)
+			}
+			if !exists {
+				model[key] = strings.Repeat("x", key%5)
+			}
+		} else {
+			_, err := tree.Delete(key)
+			if (err == nil) != exists {
+				t.Fatalf("step %d: Delete(%d) error = %v, key present = %v", step, key, err, exists)
+			}
+			delete(model, key)
+		}
+		if tree.Size() != len(model) {
+			t.Fatalf("step %d: Size() = %d, want %d", step, tree.Size(), len(model))
+		}
+	}
+
+	keys := inOrderKeys(tree.root, nil)
+	for i := 1; i < len(keys); i++ {
+		if keys[i-1] >= keys[i] {
+			t.Fatalf("keys out of order: %d before %d", keys[i-1], keys[i])
+		}
+	}
+	for key, want := range model {
+		got, err := tree.ReturnNodeValue(key)
+		if err != nil || got != want {
+			t.Errorf("ReturnNodeValue(%d) = %q, %v, want %q", key, got, err, want)
+		}
+	}
+}
+
+func TestTree_NewFunc(t *testing.T) {
+	// order strings longest first
+	tree := NewFunc[string, int](func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+	for _, key := range []string{"a", "ccc", "bb", "dddd", "b"} {
+		if _, err := tree.Insert(key, len(key)); err != nil {
+			t.Fatalf("Insert(%q) error = %v", key, err)
+		}
+	}
+	if key, err := tree.Insert("bb", 0); err == nil || key != "" {
+		t.Errorf("Insert of duplicate = %q, %v, want the zero key and an error", key, err)
+	}
+	if key, err := tree.Delete("zz"); err == nil || key != "" {
+		t.Errorf("Delete of a missing key = %q, %v, want the zero key and an error", key, err)
+	}
+	got := strings.Join(inOrderKeys(tree.root, nil), ",")
+	if want := "dddd,ccc,bb,a,b"; got != want {
+		t.Errorf("keys = %s, want %s", got, want)
+	}
+	if v, err := tree.Update("ccc", 30); err != nil || v != 30 {
+		t.Errorf("Update() = %v, %v, want 30", v, err)
+	}
+	if _, err := tree.Update("zz", 1); err == nil {
+		t.Errorf("Update of missing key returned no error")
+	}
+	if _, err := tree.ReturnNodeValue("zz"); err == nil {
+		t.Errorf("ReturnNodeValue of missing key returned no error")
+	}
+	tree.Clear()
+	if !tree.IsEmpty() || tree.Search("a") {
+		t.Errorf("tree not empty after Clear")
+	}
+}
@@ -0,0 +1,119 @@
+package bst
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// newShuffledTree returns a tree holding the keys 0, 2, 4, ..., 2*(n-1), inserted in random order,
+// each mapped to its key times ten.
+func newShuffledTree(n int) *BST {
+	tree := NewWithIntComparator()
+	for _, i := range rand.New(rand.NewSource(1)).Perm(n) {
+		tree.Insert(2*i, 20*i)
+	}
+
+	return tree
+}
+
+func TestIterator_Forward(t *testing.T) {
+	tree := newShuffledTree(1000)
+	it := tree.Iterator()
+	want := 0
+	for it.Next() {
+		if it.Key() != want || it.Value() != want*10 {
+			t.Fatalf("Next() at %d got key %v, value %v", want, it.Key(), it.Value())
+		}
+		want += 2
+	}
+	if want != 2000 {
+		t.Errorf("iterated %d entries, want 1000", want/2)
+	}
+	if it.Next() || it.Key() != nil {
+		t.Errorf("Next() past the end moved the iterator")
+	}
+	// stepping back from the end lands on the last entry
+	if !it.Prev() || it.Key() != 1998 {
+		t.Errorf("Prev() from the end got %v, want 1998", it.Key())
+	}
+}
+
+func TestIterator_Backward(t *testing.T) {
+	tree := newShuffledTree(1000)
+	it := tree.Iterator()
+	want := 1998
+	for ok := it.Last(); ok; ok = it.Prev() {
+		if it.Key() != want {
+			t.Fatalf("Prev() got key %v, want %d", it.Key(), want)
+		}
+		want -= 2
+	}
+	if want != -2 {
+		t.Errorf("stopped at %d, want -2", want)
+	}
+	if !it.Next() || it.Key() != 0 {
+		t.Errorf("Next() from the beginning got %v, want 0", it.Key())
+	}
+}
+
+func TestIterator_Seek(t *testing.T) {
+	tree := newShuffledTree(100)
+	it := tree.Iterator()
+	tests := []struct {
+		key  int
+		want interface{}
+	}{
+		{key: -5, want: 0},
+		{key: 0, want: 0},
+		{key: 51, want: 52},
+		{key: 52, want: 52},
+		{key: 198, want: 198},
+		{key: 199, want: nil},
+	}
+	for _, tt := range tests {
+		ok := it.Seek(tt.key)
+		if ok != (tt.want != nil) || it.Key() != tt.want {
+			t.Errorf("Seek(%d) = %v, key %v, want %v", tt.key, ok, it.Key(), tt.want)
+		}
+	}
+	it.Seek(51)
+	if !it.Prev() || it.Key() != 50 {
+		t.Errorf("Prev() after Seek(51) got %v, want 50", it.Key())
+	}
+}
+
+func TestIterator_Empty(t *testing.T) {
+	it := NewWithIntComparator().Iterator()
+	if it.Next() || it.Prev() || it.First() || it.Last() || it.Seek(1) {
+		t.Errorf("iterator over an empty tree moved")
+	}
+	if it.Key() != nil || it.Value() != nil {
+		t.Errorf("Key(), Value() = %v, %v, want nil, nil", it.Key(), it.Value())
+	}
+}
+
+func TestIterator_AfterDeletes(t *testing.T) {
+	tree := newShuffledTree(500)
+	r := rand.New(rand.NewSource(2))
+	var remaining []int
+	for k := 0; k < 1000; k += 2 {
+		if r.Intn(3) == 0 {
+			tree.Delete(k)
+		} else {
+			remaining = append(remaining, k)
+		}
+	}
+	var got []int
+	for it := tree.Iterator(); it.Next(); {
+		got = append(got, it.Key().(int))
+	}
+	if !sort.IntsAreSorted(got) || len(got) != len(remaining) {
+		t.Fatalf("iterated %d keys, want %d in order", len(got), len(remaining))
+	}
+	for i := range got {
+		if got[i] != remaining[i] {
+			t.Fatalf("key %d = %d, want %d", i, got[i], remaining[i])
+		}
+	}
+}
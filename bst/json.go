@@ -0,0 +1,45 @@
+package bst
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/internal/jsonpairs"
+	"reflect"
+)
+
+// SetJSONTypes takes a sample key and a sample value, such as 0 and "",
+// and makes UnmarshalJSON decode keys and values into their concrete types.
+// Without it, or if a sample is nil, encoding/json picks the types, so a number decodes as a float64,
+// which a comparator such as utils.IntComparator cannot compare.
+func (tree *BST) SetJSONTypes(key, value interface{}) {
+	tree.keyType = reflect.TypeOf(key)
+	tree.valueType = reflect.TypeOf(value)
+}
+
+// MarshalJSON implements json.Marshaler.
+// The tree is encoded as an array of {"key": ..., "value": ...} objects in ascending key order,
+// since a JSON object does not keep the order of its members.
+func (tree *BST) MarshalJSON() ([]byte, error) {
+	return jsonpairs.Marshal(func(fn func(key, value interface{}) bool) {
+		tree.Walk(trees.InOrder, fn)
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+// It replaces the contents of the tree with the entries of an array in the format MarshalJSON writes.
+// The tree must already have a comparator, so create it with NewWith or one of its variants,
+// and call SetJSONTypes first to choose the types of the keys and values.
+// Entries already in ascending key order are loaded in O(n) with FromSorted's algorithm; others are sorted first.
+// The function returns an error, leaving the tree unchanged, if the JSON is malformed, two keys are equal,
+// or the comparator cannot compare the decoded keys, as when SetJSONTypes was not called for int keys.
+func (tree *BST) UnmarshalJSON(data []byte) error {
+	if tree.comparator == nil {
+		return fmt.Errorf("JSON ERROR: the tree has no comparator; create it with NewWith before decoding into it")
+	}
+	keys, values, err := jsonpairs.Unmarshal(data, tree.comparator, tree.keyType, tree.valueType)
+	if err != nil {
+		return err
+	}
+
+	return tree.loadSorted(keys, values)
+}
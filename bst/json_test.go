@@ -0,0 +1,102 @@
+package bst
+
+import (
+	"encoding/json"
+	"github.com/emirpasic/gods/utils"
+	"reflect"
+	"testing"
+)
+
+func TestBST_JSON(t *testing.T) {
+	tree := newSmallTree(t)
+	data, err := json.Marshal(tree)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	want := `[{"key":1,"value":"one"},{"key":2,"value":"two"},{"key":3,"value":"three"}]`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	decoded := NewWithIntComparator()
+	decoded.SetJSONTypes(0, "")
+	if err := json.Unmarshal(data, decoded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if got := decoded.Size(); got != 3 {
+		t.Errorf("Size() after Unmarshal() = %d, want 3", got)
+	}
+	for key, value := range map[int]string{1: "one", 2: "two", 3: "three"} {
+		if got, err := decoded.ReturnNodeValue(key); err != nil || got != value {
+			t.Errorf("ReturnNodeValue(%d) = %v, %v, want %v", key, got, err, value)
+		}
+	}
+
+	empty, err := json.Marshal(NewWithIntComparator())
+	if err != nil || string(empty) != "[]" {
+		t.Errorf("Marshal() of an empty tree = %s, %v, want []", empty, err)
+	}
+}
+
+func TestBST_UnmarshalJSONUnsorted(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.SetJSONTypes(0, nil)
+	data := `[{"key":5,"value":[1,2]},{"key":1},{"key":3,"value":null},{"key":2,"value":"two"},{"key":4,"value":4}]`
+	if err := json.Unmarshal([]byte(data), tree); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	var keys, values []interface{}
+	it := tree.Iterator()
+	for it.Next() {
+		keys = append(keys, it.Key())
+		values = append(values, it.Value())
+	}
+	if want := []interface{}{1, 2, 3, 4, 5}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("keys after Unmarshal() = %v, want %v", keys, want)
+	}
+	// without a value type, encoding/json picks the types
+	if want := []interface{}{nil, "two", nil, float64(4), []interface{}{float64(1), float64(2)}}; !reflect.DeepEqual(values, want) {
+		t.Errorf("values after Unmarshal() = %v, want %v", values, want)
+	}
+}
+
+func TestBST_UnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"malformed", `[{"key":1,`},
+		{"not an array", `{"1":"one"}`},
+		{"missing key", `[{"value":"one"}]`},
+		{"wrong key type", `[{"key":"one","value":1}]`},
+		{"duplicate", `[{"key":1,"value":"a"},{"key":2,"value":"b"},{"key":1,"value":"c"}]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newSmallTree(t)
+			tree.SetJSONTypes(0, "")
+			if err := json.Unmarshal([]byte(tt.data), tree); err == nil {
+				t.Fatalf("Unmarshal(%s) returned no error", tt.data)
+			}
+			if tree.Size() != 3 || !tree.Search(2) {
+				t.Errorf("Unmarshal(%s) changed the tree to %v", tt.data, tree)
+			}
+		})
+	}
+	if err := json.Unmarshal([]byte(`[]`), &BST{}); err == nil {
+		t.Errorf("Unmarshal() into a tree without a comparator returned no error")
+	}
+}
+
+func TestBST_UnmarshalJSONDefaultTypes(t *testing.T) {
+	tree := NewWith(utils.Float64Comparator)
+	if err := json.Unmarshal([]byte(`[{"key":2.5,"value":true},{"key":-1,"value":false}]`), tree); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if value, err := tree.ReturnNodeValue(2.5); err != nil || value != true {
+		t.Errorf("ReturnNodeValue(2.5) = %v, %v, want true", value, err)
+	}
+	if key, _, found := tree.Min(); !found || key != -1.0 {
+		t.Errorf("Min() = %v, want -1", key)
+	}
+}
@@ -127,11 +127,11 @@ func (node *Node) successor() *Node {
 
 // predecessor returns the node with the largest key smaller than the node the method is called on
 func (node *Node) predecessor() *Node {
-	// successor is the furthest left child of the right subtree
+	// predecessor is the furthest right child of the left subtree
 	if node.leftChild() != nil {
-		return node.subtreeMax(node.rightChild())
+		return node.subtreeMax(node.leftChild())
 	}
-	// otherwise, work up and to the right of the subtrees
+	// otherwise, work up and to the left of the subtrees
 	parent := node.getParent()
 	temp := node
 	for parent != nil && temp == parent.leftChild() {
@@ -161,3 +161,56 @@ func (node *Node) subtreeMax(child *Node) *Node {
 
 	return temp
 }
+
+// Left returns the node's left child, or nil if the node has none or is nil.
+// Together with Right, Parent, Key, and Value, it lets callers read the shape of a tree from Root.
+// The links are read-only: only the tree's methods move nodes. The exported Data field is not,
+// and setting Data.Key through it breaks the key order that searches rely on, so change entries with Update.
+func (node *Node) Left() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.left
+}
+
+// Right returns the node's right child, or nil if the node has none or is nil.
+func (node *Node) Right() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.right
+}
+
+// Parent returns the node's parent, or nil if the node is the root or is nil.
+func (node *Node) Parent() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.parent
+}
+
+// Key returns the node's key, or nil if the node is nil.
+func (node *Node) Key() interface{} {
+	if node == nil {
+		return nil
+	}
+
+	return node.Data.Key
+}
+
+// Value returns the node's value, or nil if the node is nil.
+func (node *Node) Value() interface{} {
+	if node == nil {
+		return nil
+	}
+
+	return node.Data.Value
+}
+
+// IsLeaf reports whether the node has no children. It returns false if the node is nil.
+func (node *Node) IsLeaf() bool {
+	return node != nil && node.left == nil && node.right == nil
+}
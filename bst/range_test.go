@@ -0,0 +1,83 @@
+package bst
+
+import (
+	"github.com/chancetudor/trees"
+	"testing"
+)
+
+// collectRange returns the keys Range visits.
+func collectRange(tree *BST, lo, hi trees.Bound) []interface{} {
+	keys := []interface{}{}
+	tree.Range(lo, hi, func(key, value interface{}) bool {
+		keys = append(keys, key)
+		return true
+	})
+
+	return keys
+}
+
+func TestRange(t *testing.T) {
+	tree := newShuffledTree(10) // keys 0, 2, ..., 18
+	tests := []struct {
+		name   string
+		lo, hi trees.Bound
+		want   []interface{}
+	}{
+		{"inclusive", trees.Including(4), trees.Including(10), []interface{}{4, 6, 8, 10}},
+		{"exclusive", trees.Excluding(4), trees.Excluding(10), []interface{}{6, 8}},
+		{"between keys", trees.Including(3), trees.Excluding(9), []interface{}{4, 6, 8}},
+		{"unbounded below", trees.Bound{}, trees.Excluding(6), []interface{}{0, 2, 4}},
+		{"unbounded above", trees.Excluding(14), trees.Bound{}, []interface{}{16, 18}},
+		{"everything", trees.Bound{}, trees.Bound{}, []interface{}{0, 2, 4, 6, 8, 10, 12, 14, 16, 18}},
+		{"single key", trees.Including(8), trees.Including(8), []interface{}{8}},
+		{"empty", trees.Excluding(8), trees.Excluding(10), []interface{}{}},
+		{"reversed", trees.Including(10), trees.Including(4), []interface{}{}},
+		{"above every key", trees.Including(19), trees.Bound{}, []interface{}{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := collectRange(tree, tt.lo, tt.hi)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Range() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Range() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRange_StopsEarly(t *testing.T) {
+	tree := newShuffledTree(100)
+	calls := 0
+	tree.Range(trees.Including(10), trees.Bound{}, func(key, value interface{}) bool {
+		calls++
+		return key.(int) < 20
+	})
+	if calls != 6 {
+		t.Errorf("fn called %d times, want 6", calls)
+	}
+}
+
+func TestRangeIterator(t *testing.T) {
+	tree := newShuffledTree(100)
+	it := tree.RangeIterator(trees.Excluding(150), trees.Including(160))
+	if it.Key() != nil {
+		t.Errorf("Key() before Next() = %v, want nil", it.Key())
+	}
+	want := 152
+	for it.Next() {
+		if it.Key() != want || it.Value() != want*10 {
+			t.Fatalf("got %v: %v, want %d", it.Key(), it.Value(), want)
+		}
+		want += 2
+	}
+	if want != 162 {
+		t.Errorf("stopped before %d, want 162", want)
+	}
+	if it.Next() || it.Key() != nil {
+		t.Errorf("Next() after the range moved the iterator")
+	}
+}
@@ -2,6 +2,7 @@ package bst
 
 import (
 	"github.com/emirpasic/gods/utils"
+	"reflect"
 )
 
 /* Package bst implements a binary search tree in Go
@@ -20,6 +21,8 @@ type BST struct {
 	root       *Node            // the root Node
 	comparator utils.Comparator // the key comparator
 	size       int              // number of nodes in the tree
+	keyType    reflect.Type     // the type UnmarshalJSON decodes keys into, or nil
+	valueType  reflect.Type     // the type UnmarshalJSON decodes values into, or nil
 }
 
 // NewWith returns a pointer to a BST where root is nil, size is 0,
@@ -125,6 +128,100 @@ func (tree *BST) findNode(key interface{}) (*Node, error) {
 	return nil, NewNilNodeError(key)
 }
 
+// ceilingNode takes a key and returns the node with the smallest key greater than or equal to it.
+// Returns nil if no such node exists.
+func (tree *BST) ceilingNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		compare := tree.comparator(key, tempNode.key())
+		switch {
+		case compare < 0:
+			candidate = tempNode
+			tempNode = tempNode.leftChild()
+		case compare > 0:
+			tempNode = tempNode.rightChild()
+		case compare == 0:
+			return tempNode
+		}
+	}
+
+	return candidate
+}
+
+// floorNode takes a key and returns the node with the largest key less than or equal to it.
+// Returns nil if no such node exists.
+func (tree *BST) floorNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		compare := tree.comparator(key, tempNode.key())
+		switch {
+		case compare < 0:
+			tempNode = tempNode.leftChild()
+		case compare > 0:
+			candidate = tempNode
+			tempNode = tempNode.rightChild()
+		case compare == 0:
+			return tempNode
+		}
+	}
+
+	return candidate
+}
+
+// lowerNode takes a key and returns the node with the largest key strictly less than it.
+// Returns nil if no such node exists.
+func (tree *BST) lowerNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		if tree.comparator(key, tempNode.key()) > 0 {
+			candidate = tempNode
+			tempNode = tempNode.rightChild()
+		} else {
+			tempNode = tempNode.leftChild()
+		}
+	}
+
+	return candidate
+}
+
+// higherNode takes a key and returns the node with the smallest key strictly greater than it.
+// Returns nil if no such node exists.
+func (tree *BST) higherNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		if tree.comparator(key, tempNode.key()) < 0 {
+			candidate = tempNode
+			tempNode = tempNode.leftChild()
+		} else {
+			tempNode = tempNode.rightChild()
+		}
+	}
+
+	return candidate
+}
+
+// minNode returns the node with the smallest key in the tree, or nil if the tree is empty.
+func (tree *BST) minNode() *Node {
+	if tree.IsEmpty() {
+		return nil
+	}
+
+	return tree.Root().subtreeMin(tree.Root())
+}
+
+// maxNode returns the node with the largest key in the tree, or nil if the tree is empty.
+func (tree *BST) maxNode() *Node {
+	if tree.IsEmpty() {
+		return nil
+	}
+
+	return tree.Root().subtreeMax(tree.Root())
+}
+
 // Update takes a key and a value and updates a node with the existing key with the new value.
 // Returns the new value of the node or an error, if there was one.
 func (tree *BST) Update(key interface{}, value interface{}) (interface{}, error) {
@@ -146,6 +243,73 @@ func (tree *BST) ReturnNodeValue(key interface{}) (interface{}, error) {
 	return matchingNode.value(), nil
 }
 
+// Floor takes a key and returns the entry with the largest key less than or equal to it.
+// The boolean is false if no such entry exists.
+func (tree *BST) Floor(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.floorNode(key))
+}
+
+// Ceiling takes a key and returns the entry with the smallest key greater than or equal to it.
+// The boolean is false if no such entry exists.
+func (tree *BST) Ceiling(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.ceilingNode(key))
+}
+
+// Lower takes a key and returns the entry with the largest key strictly less than it.
+// The boolean is false if no such entry exists.
+func (tree *BST) Lower(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.lowerNode(key))
+}
+
+// Higher takes a key and returns the entry with the smallest key strictly greater than it.
+// The boolean is false if no such entry exists.
+func (tree *BST) Higher(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.higherNode(key))
+}
+
+// Min returns the entry with the smallest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *BST) Min() (interface{}, interface{}, bool) {
+	return entryOf(tree.minNode())
+}
+
+// Max returns the entry with the largest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *BST) Max() (interface{}, interface{}, bool) {
+	return entryOf(tree.maxNode())
+}
+
+// PopMin removes and returns the entry with the smallest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *BST) PopMin() (interface{}, interface{}, bool) {
+	return tree.pop(tree.minNode())
+}
+
+// PopMax removes and returns the entry with the largest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *BST) PopMax() (interface{}, interface{}, bool) {
+	return tree.pop(tree.maxNode())
+}
+
+// pop removes a node from the tree and returns its entry, or nil, nil, and false if the node is nil.
+func (tree *BST) pop(node *Node) (interface{}, interface{}, bool) {
+	key, value, found := entryOf(node)
+	if found {
+		tree.deleteNode(node)
+	}
+
+	return key, value, found
+}
+
+// entryOf returns the key and value of a node and true, or nil, nil, and false if the node is nil.
+func entryOf(node *Node) (interface{}, interface{}, bool) {
+	if node == nil {
+		return nil, nil, false
+	}
+
+	return node.key(), node.value(), true
+}
+
 // Delete takes a key, removes the node from the tree, and decrements the size of the tree.
 // The function returns the key of the deleted node and an error, if there was one.
 func (tree *BST) Delete(key interface{}) (interface{}, error) {
@@ -155,11 +319,18 @@ func (tree *BST) Delete(key interface{}) (interface{}, error) {
 		return nil, err
 	}
 	nodeToDeleteKey := nodeToDelete.key()
+	tree.deleteNode(nodeToDelete)
 
+	return nodeToDeleteKey, nil
+}
+
+// deleteNode removes a node from the tree and decrements the size of the tree.
+func (tree *BST) deleteNode(nodeToDelete *Node) {
 	// node is already a leaf
 	if nodeToDelete.isLeaf() {
 		tree.pruneLeaf(nodeToDelete)
-		return nodeToDeleteKey, nil
+		tree.setSize(tree.Size() - 1)
+		return
 	}
 
 	switch {
@@ -181,7 +352,6 @@ func (tree *BST) Delete(key interface{}) (interface{}, error) {
 	}
 
 	tree.setSize(tree.Size() - 1)
-	return nodeToDeleteKey, nil
 }
 
 // replaceSubTree replaces the node to delete with a new root node of a subtree.
@@ -206,11 +376,11 @@ func (tree *BST) replaceSubTree(toDelete *Node, replacementNode *Node) {
 }
 
 // pruneLeaf removes a leaf from the tree.
-// If the node to delete is the root, tree.Clear() is called.
+// If the node to delete is the root, the root is set to nil.
 // The function does not decrement the size of the tree.
 func (tree *BST) pruneLeaf(toDelete *Node) {
 	if toDelete.isRoot() {
-		tree.Clear()
+		tree.setRoot(nil)
 		return
 	}
 	parent := toDelete.getParent()
@@ -0,0 +1,113 @@
+package bst
+
+import (
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// hasViolation reports whether err is a *ValidationError listing a violation of rule by key.
+func hasViolation(err error, key interface{}, rule Rule) bool {
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		return false
+	}
+	for _, v := range validationErr.Violations {
+		if v.Key == key && v.Rule == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newFifteen returns the perfectly balanced tree of the keys 0 to 14.
+func newFifteen(t *testing.T) *BST {
+	keys := make([]interface{}, 15)
+	for i := range keys {
+		keys[i] = i
+	}
+	tree, err := FromSorted(utils.IntComparator, keys, nil)
+	if err != nil {
+		t.Fatalf("FromSorted() error = %v", err)
+	}
+
+	return tree
+}
+
+func TestBST_Validate(t *testing.T) {
+	tree := NewWithIntComparator()
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("Validate() of an empty tree error = %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		key := rng.Intn(500)
+		if tree.Search(key) {
+			tree.Delete(key)
+		} else {
+			tree.Insert(key, i)
+		}
+		if i%100 == 0 {
+			if err := tree.Validate(); err != nil {
+				t.Fatalf("Validate() after %d operations error = %v", i, err)
+			}
+		}
+	}
+}
+
+func TestBST_ValidateViolations(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(tree *BST) *Node // breaks the tree and returns the node to blame, or nil
+		rule    Rule
+	}{
+		{"order", func(tree *BST) *Node {
+			node := tree.Root().leftChild()
+			node.setKey(1000)
+			// the walk meets 1000 first, so the key after it is out of order
+			return node.successor()
+		}, RuleOrder},
+		{"parent", func(tree *BST) *Node {
+			node := tree.Root().rightChild().leftChild()
+			node.setParent(tree.Root())
+			return node
+		}, RuleParent},
+		{"structure", func(tree *BST) *Node {
+			leaf := tree.minNode()
+			leaf.setLeftChild(tree.Root())
+			return tree.Root()
+		}, RuleStructure},
+		{"size", func(tree *BST) *Node {
+			tree.setSize(tree.Size() + 1)
+			return nil
+		}, RuleSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newFifteen(t)
+			var key interface{}
+			if node := tt.corrupt(tree); node != nil {
+				key = node.key()
+			}
+			err := tree.Validate()
+			if !hasViolation(err, key, tt.rule) {
+				t.Fatalf("Validate() = %v, want a %s violation at %v", err, tt.rule, key)
+			}
+			if !strings.Contains(err.Error(), string(tt.rule)) {
+				t.Errorf("Error() = %q does not name %s", err.Error(), tt.rule)
+			}
+		})
+	}
+}
+
+func TestBST_ValidateDegenerate(t *testing.T) {
+	tree := NewWithIntComparator()
+	for i := 0; i < 2000; i++ {
+		tree.Insert(i, i)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Errorf("Validate() of a right-leaning chain error = %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package bst
+
+import (
+	"github.com/chancetudor/trees"
+)
+
+// Walk calls fn for every entry of the tree, visiting nodes in the given order; see package trees.
+// The traversal is iterative, following parent pointers for the depth-first orders and a queue for LevelOrder,
+// so a degenerate tree cannot overflow the goroutine stack.
+// If fn returns false, the walk stops. An unknown order visits nothing.
+// fn must not insert into or delete from the tree.
+func (tree *BST) Walk(order trees.TraversalOrder, fn func(key, value interface{}) bool) {
+	tree.walk(order, func(node *Node) bool {
+		return fn(node.key(), node.value())
+	})
+}
+
+// walk calls fn for every node of the tree in the given order, until fn returns false.
+func (tree *BST) walk(order trees.TraversalOrder, fn func(node *Node) bool) {
+	root := tree.Root()
+	if root == nil {
+		return
+	}
+	switch order {
+	case trees.InOrder:
+		for node := tree.minNode(); node != nil; node = node.successor() {
+			if !fn(node) {
+				return
+			}
+		}
+	case trees.ReverseInOrder:
+		for node := tree.maxNode(); node != nil; node = node.predecessor() {
+			if !fn(node) {
+				return
+			}
+		}
+	case trees.PreOrder:
+		for node := root; node != nil; node = preOrderNext(node) {
+			if !fn(node) {
+				return
+			}
+		}
+	case trees.PostOrder:
+		for node := deepestFirst(root); node != nil; node = postOrderNext(node) {
+			if !fn(node) {
+				return
+			}
+		}
+	case trees.LevelOrder:
+		queue := []*Node{root}
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			if !fn(node) {
+				return
+			}
+			if node.leftChild() != nil {
+				queue = append(queue, node.leftChild())
+			}
+			if node.rightChild() != nil {
+				queue = append(queue, node.rightChild())
+			}
+		}
+	}
+}
+
+// preOrderNext returns the node visited after node in a pre-order walk, or nil if node is the last one.
+func preOrderNext(node *Node) *Node {
+	if node.leftChild() != nil {
+		return node.leftChild()
+	}
+	if node.rightChild() != nil {
+		return node.rightChild()
+	}
+	// climb until we leave a left subtree whose parent has a right child
+	for parent := node.getParent(); parent != nil; node, parent = parent, parent.getParent() {
+		if node == parent.leftChild() && parent.rightChild() != nil {
+			return parent.rightChild()
+		}
+	}
+
+	return nil
+}
+
+// deepestFirst returns the first node of a post-order walk of the subtree rooted at node:
+// the leaf reached by going left whenever possible and right otherwise.
+func deepestFirst(node *Node) *Node {
+	for {
+		switch {
+		case node.leftChild() != nil:
+			node = node.leftChild()
+		case node.rightChild() != nil:
+			node = node.rightChild()
+		default:
+			return node
+		}
+	}
+}
+
+// postOrderNext returns the node visited after node in a post-order walk, or nil if node is the last one.
+func postOrderNext(node *Node) *Node {
+	parent := node.getParent()
+	if parent == nil {
+		return nil
+	}
+	if node == parent.leftChild() && parent.rightChild() != nil {
+		return deepestFirst(parent.rightChild())
+	}
+
+	return parent
+}
@@ -0,0 +1,115 @@
+package bst
+
+import (
+	"github.com/chancetudor/trees"
+	"reflect"
+	"testing"
+)
+
+// walkRecursive returns the keys of the subtree rooted at node in the given depth-first order,
+// computed recursively as a reference for Walk.
+func walkRecursive(node *Node, order trees.TraversalOrder) []interface{} {
+	if node == nil {
+		return nil
+	}
+	left, right := walkRecursive(node.leftChild(), order), walkRecursive(node.rightChild(), order)
+	self := []interface{}{node.key()}
+	switch order {
+	case trees.PreOrder:
+		return append(append(self, left...), right...)
+	case trees.PostOrder:
+		return append(append(left, right...), self...)
+	case trees.ReverseInOrder:
+		return append(append(right, self...), left...)
+	default:
+		return append(append(left, self...), right...)
+	}
+}
+
+// levels returns the keys of the tree level by level, from left to right.
+func levels(tree *BST) []interface{} {
+	var keys []interface{}
+	for level := []*Node{tree.Root()}; len(level) > 0 && level[0] != nil; {
+		var next []*Node
+		for _, node := range level {
+			keys = append(keys, node.key())
+			for _, child := range []*Node{node.leftChild(), node.rightChild()} {
+				if child != nil {
+					next = append(next, child)
+				}
+			}
+		}
+		level = next
+	}
+
+	return keys
+}
+
+func TestBST_Walk(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 500} {
+		tree := newShuffledTree(n)
+		for _, order := range []trees.TraversalOrder{trees.InOrder, trees.PreOrder, trees.PostOrder,
+			trees.LevelOrder, trees.ReverseInOrder} {
+			want := walkRecursive(tree.Root(), order)
+			if order == trees.LevelOrder {
+				want = levels(tree)
+			}
+			var got []interface{}
+			tree.Walk(order, func(key, value interface{}) bool {
+				if value != key.(int)*10 {
+					t.Errorf("Walk(%v) got value %v for key %v", order, value, key)
+				}
+				got = append(got, key)
+				return true
+			})
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Walk(%v) of %d keys = %v, want %v", order, n, got, want)
+			}
+		}
+	}
+}
+
+func TestBST_WalkStopsEarly(t *testing.T) {
+	tree := newShuffledTree(100)
+	for _, order := range []trees.TraversalOrder{trees.InOrder, trees.PreOrder, trees.PostOrder,
+		trees.LevelOrder, trees.ReverseInOrder} {
+		count := 0
+		tree.Walk(order, func(key, value interface{}) bool {
+			count++
+			return count < 10
+		})
+		if count != 10 {
+			t.Errorf("Walk(%v) called fn %d times after it returned false, want 10", order, count)
+		}
+	}
+	tree.Walk(trees.TraversalOrder(-1), func(key, value interface{}) bool {
+		t.Fatalf("Walk() with an unknown order visited %v", key)
+		return false
+	})
+}
+
+func TestBST_WalkDegenerate(t *testing.T) {
+	// a right-leaning chain, as sequential inserts produce, linked directly to keep the test fast
+	const n = 1000000
+	tree := NewWithIntComparator()
+	tree.setRoot(NewNode(0, nil))
+	last := tree.Root()
+	for i := 1; i < n; i++ {
+		node := NewNode(i, nil)
+		node.setParent(last)
+		last.setRightChild(node)
+		last = node
+	}
+	tree.size = n
+	for _, order := range []trees.TraversalOrder{trees.InOrder, trees.PreOrder, trees.PostOrder,
+		trees.LevelOrder, trees.ReverseInOrder} {
+		count := 0
+		tree.Walk(order, func(key, value interface{}) bool {
+			count++
+			return true
+		})
+		if count != n {
+			t.Errorf("Walk(%v) visited %d of %d nodes", order, count, n)
+		}
+	}
+}
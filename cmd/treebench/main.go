@@ -0,0 +1,103 @@
+// Command treebench benchmarks bst.BST, avl.AVL, and rbt.RBT against a Go map and the gods red-black tree,
+// and writes the results as a Markdown or CSV table.
+//
+// Usage:
+//
+//	treebench [flags]
+//
+// For example, to compare searches on 10,000 and 100,000 random keys:
+//
+//	treebench -ops Search -workloads random -sizes 10000,100000
+//
+// Every row reports the time, bytes, and allocations of one operation,
+// and how many times slower it is than the fastest implementation on the same operation, workload, and size.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/chancetudor/trees/internal/bench"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func main() {
+	// testing.Benchmark reads the -test.* flags, which testing.Init registers on flag.CommandLine;
+	// the tool parses its own FlagSet, so they stay out of its usage message
+	testing.Init()
+	flags := flag.NewFlagSet("treebench", flag.ExitOnError)
+	sizes := flags.String("sizes", "1000,10000,100000", "comma-separated map sizes")
+	ops := flags.String("ops", strings.Join(bench.Ops, ","), "comma-separated operations")
+	workloads := flags.String("workloads", "sequential,random,zipfian,zigzag", "comma-separated workloads")
+	impls := flags.String("impls", "bst,avl,rbt,gomap,gods-rbt", "comma-separated implementations")
+	format := flags.String("format", "markdown", "output format: markdown or csv")
+	output := flags.String("o", "", "output file (default standard output)")
+	benchtime := flags.String("benchtime", "1s", "run time per benchmark, or a count such as 10000x")
+	flags.Parse(os.Args[1:])
+
+	if err := run(*sizes, *ops, *workloads, *impls, *format, *output, *benchtime); err != nil {
+		fmt.Fprintln(os.Stderr, "treebench:", err)
+		os.Exit(1)
+	}
+}
+
+// run measures the selected cases and writes the report.
+func run(sizes, ops, workloads, impls, format, output, benchtime string) error {
+	if err := flag.Set("test.benchtime", benchtime); err != nil {
+		return fmt.Errorf("invalid -benchtime: %w", err)
+	}
+	var ns []int
+	for _, s := range strings.Split(sizes, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid size %q", s)
+		}
+		ns = append(ns, n)
+	}
+	write := map[string]func(io.Writer, []bench.Result) error{
+		"markdown": bench.WriteMarkdown,
+		"csv":      bench.WriteCSV,
+	}[format]
+	if write == nil {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	selected := func(list, name string) bool {
+		for _, s := range strings.Split(list, ",") {
+			if strings.TrimSpace(s) == name {
+				return true
+			}
+		}
+		return false
+	}
+	var cases []bench.Case
+	for _, c := range bench.Cases(ns) {
+		if selected(ops, c.Op) && selected(workloads, c.Workload) && selected(impls, c.Impl) {
+			cases = append(cases, c)
+		}
+	}
+	if len(cases) == 0 {
+		return fmt.Errorf("no benchmarks match the -ops, -workloads, and -impls flags")
+	}
+
+	results := bench.Measure(cases, func(c bench.Case) {
+		fmt.Fprintln(os.Stderr, c.Name())
+	})
+	if output == "" {
+		return write(os.Stdout, results)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	if err := write(f, results); err != nil {
+		f.Close()
+		return err
+	}
+
+	// a failed Close can mean the report was not fully written
+	return f.Close()
+}
@@ -0,0 +1,103 @@
+package trees
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Codec converts keys or values to and from bytes for the MarshalBinary and UnmarshalBinary methods
+// of avl.AVL and rbt.RBT. An encoding must say where it ends, so that Decode can find the next one.
+type Codec interface {
+	// Append takes a buffer and a key or value, appends the encoding of the key or value to the buffer,
+	// and returns the extended buffer or an error, if the key or value cannot be encoded.
+	Append(buf []byte, v interface{}) ([]byte, error)
+	// Decode takes bytes that begin with an encoding written by Append
+	// and returns the decoded key or value and the number of bytes the encoding used.
+	Decode(data []byte) (interface{}, int, error)
+}
+
+// CodecFuncs adapts a pair of functions to the Codec interface.
+type CodecFuncs struct {
+	AppendFunc func(buf []byte, v interface{}) ([]byte, error) // appends the encoding of v to buf
+	DecodeFunc func(data []byte) (interface{}, int, error)     // decodes the encoding at the start of data
+}
+
+// Append calls c.AppendFunc.
+func (c CodecFuncs) Append(buf []byte, v interface{}) ([]byte, error) {
+	return c.AppendFunc(buf, v)
+}
+
+// Decode calls c.DecodeFunc.
+func (c CodecFuncs) Decode(data []byte) (interface{}, int, error) {
+	return c.DecodeFunc(data)
+}
+
+// IntCodec encodes ints as variable-length zig-zag integers, so small magnitudes take few bytes.
+type IntCodec struct{}
+
+// Append appends the encoding of v, which must be an int, to buf.
+func (IntCodec) Append(buf []byte, v interface{}) ([]byte, error) {
+	i, ok := v.(int)
+	if !ok {
+		return nil, fmt.Errorf("CODEC ERROR: IntCodec cannot encode %T", v)
+	}
+
+	return binary.AppendVarint(buf, int64(i)), nil
+}
+
+// Decode decodes the int at the start of data.
+func (IntCodec) Decode(data []byte) (interface{}, int, error) {
+	i, n := binary.Varint(data)
+	if n <= 0 || i < math.MinInt || i > math.MaxInt {
+		return nil, 0, fmt.Errorf("CODEC ERROR: invalid int")
+	}
+
+	return int(i), n, nil
+}
+
+// StringCodec encodes strings as their length followed by their bytes.
+type StringCodec struct{}
+
+// Append appends the encoding of v, which must be a string, to buf.
+func (StringCodec) Append(buf []byte, v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("CODEC ERROR: StringCodec cannot encode %T", v)
+	}
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+
+	return append(buf, s...), nil
+}
+
+// Decode decodes the string at the start of data.
+func (StringCodec) Decode(data []byte) (interface{}, int, error) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || length > uint64(len(data)-n) {
+		return nil, 0, fmt.Errorf("CODEC ERROR: invalid string")
+	}
+
+	return string(data[n : n+int(length)]), n + int(length), nil
+}
+
+// Float64Codec encodes float64s as their eight IEEE 754 bytes, little-endian.
+type Float64Codec struct{}
+
+// Append appends the encoding of v, which must be a float64, to buf.
+func (Float64Codec) Append(buf []byte, v interface{}) ([]byte, error) {
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("CODEC ERROR: Float64Codec cannot encode %T", v)
+	}
+
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f)), nil
+}
+
+// Decode decodes the float64 at the start of data.
+func (Float64Codec) Decode(data []byte) (interface{}, int, error) {
+	if len(data) < 8 {
+		return nil, 0, fmt.Errorf("CODEC ERROR: invalid float64")
+	}
+
+	return math.Float64frombits(binary.LittleEndian.Uint64(data)), 8, nil
+}
@@ -0,0 +1,45 @@
+package trees
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCodecs(t *testing.T) {
+	tests := []struct {
+		name   string
+		codec  Codec
+		values []interface{}
+	}{
+		{"int", IntCodec{}, []interface{}{0, 1, -1, 300, math.MaxInt, math.MinInt}},
+		{"string", StringCodec{}, []interface{}{"", "a", "héllo, wörld", string(make([]byte, 1000))}},
+		{"float64", Float64Codec{}, []interface{}{0.0, -2.5, math.Inf(1), math.MaxFloat64}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf []byte
+			for _, v := range tt.values {
+				var err error
+				if buf, err = tt.codec.Append(buf, v); err != nil {
+					t.Fatalf("Append(%v) error = %v", v, err)
+				}
+			}
+			for _, want := range tt.values {
+				got, n, err := tt.codec.Decode(buf)
+				if err != nil || got != want {
+					t.Fatalf("Decode() = %v, %v, want %v", got, err, want)
+				}
+				buf = buf[n:]
+			}
+			if len(buf) != 0 {
+				t.Errorf("Decode() left %d bytes", len(buf))
+			}
+			if _, err := tt.codec.Append(nil, struct{}{}); err == nil {
+				t.Errorf("Append() of a struct returned no error")
+			}
+			if _, _, err := tt.codec.Decode(nil); err == nil {
+				t.Errorf("Decode() of no bytes returned no error")
+			}
+		})
+	}
+}
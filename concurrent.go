@@ -0,0 +1,260 @@
+package trees
+
+import (
+	"reflect"
+	"sync"
+)
+
+// ConcurrentMap makes an OrderedMap, such as a bst.BST, avl.AVL, or rbt.RBT, safe for concurrent use.
+// Every operation holds a reader/writer lock, so any number of lookups run in parallel
+// while each change runs alone.
+// On top of the OrderedMap methods, it offers conditional operations, each atomic,
+// and Batch, which applies several changes atomically.
+// The wrapped map must not be used directly while the ConcurrentMap is in use.
+type ConcurrentMap struct {
+	mu sync.RWMutex // guards m
+	m  OrderedMap   // the wrapped map
+}
+
+// NewConcurrent takes an OrderedMap and returns a pointer to a ConcurrentMap wrapping it.
+func NewConcurrent(m OrderedMap) *ConcurrentMap {
+	return &ConcurrentMap{m: m}
+}
+
+// Search takes a key and returns a boolean stating whether the key was found or not.
+func (c *ConcurrentMap) Search(key interface{}) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.m.Search(key)
+}
+
+// ReturnNodeValue takes a key and returns the value associated with the key or an error, if there was one.
+func (c *ConcurrentMap) ReturnNodeValue(key interface{}) (interface{}, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.m.ReturnNodeValue(key)
+}
+
+// Size returns the number of entries in the map.
+func (c *ConcurrentMap) Size() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.m.Size()
+}
+
+// IsEmpty returns a boolean stating whether the map is empty or not.
+func (c *ConcurrentMap) IsEmpty() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.m.IsEmpty()
+}
+
+// Insert takes a key and a value and stores them as a new entry.
+// It returns the inserted key or an error if the key already exists.
+func (c *ConcurrentMap) Insert(key, value interface{}) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.m.Insert(key, value)
+}
+
+// Update takes a key and a value and replaces the value of an existing entry.
+// It returns the new value or an error if the key does not exist.
+func (c *ConcurrentMap) Update(key, value interface{}) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.m.Update(key, value)
+}
+
+// Delete takes a key and removes its entry.
+// It returns the deleted key or an error if the key does not exist.
+func (c *ConcurrentMap) Delete(key interface{}) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.m.Delete(key)
+}
+
+// Clear removes every entry.
+func (c *ConcurrentMap) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m.Clear()
+}
+
+// LoadOrStore takes a key and a value. If the key exists, the function returns its value and true;
+// otherwise it inserts the entry and returns value and false.
+func (c *ConcurrentMap) LoadOrStore(key, value interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if actual, err := c.m.ReturnNodeValue(key); err == nil {
+		return actual, true
+	}
+	// the key was missing under the write lock, so Insert cannot fail with the DuplicateError,
+	// the only error the library's maps return from it
+	_, _ = c.m.Insert(key, value)
+
+	return value, false
+}
+
+// LoadAndDelete takes a key and removes its entry, returning the value it held and true,
+// or nil and false if the key does not exist.
+func (c *ConcurrentMap) LoadAndDelete(key interface{}) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, err := c.m.ReturnNodeValue(key)
+	if err != nil {
+		return nil, false
+	}
+	if _, err := c.m.Delete(key); err != nil {
+		return nil, false
+	}
+
+	return value, true
+}
+
+// CompareAndSwap takes a key, an old value, and a new value, and replaces the key's value with new
+// if the key exists and its value equals old. It reports whether it swapped.
+// The values are compared with ==; a value that == cannot compare, such as a slice or a map, never equals old.
+func (c *ConcurrentMap) CompareAndSwap(key, old, new interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if value, err := c.m.ReturnNodeValue(key); err != nil || !equal(value, old) {
+		return false
+	}
+	_, err := c.m.Update(key, new)
+
+	return err == nil
+}
+
+// CompareAndDelete takes a key and an old value, and deletes the key's entry
+// if the key exists and its value equals old. It reports whether it deleted.
+// The values are compared as in CompareAndSwap.
+func (c *ConcurrentMap) CompareAndDelete(key, old interface{}) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if value, err := c.m.ReturnNodeValue(key); err != nil || !equal(value, old) {
+		return false
+	}
+	_, err := c.m.Delete(key)
+
+	return err == nil
+}
+
+// equal reports whether a == b, treating values that == would panic on, such as slices and maps, as unequal.
+func equal(a, b interface{}) bool {
+	for _, v := range []interface{}{a, b} {
+		if v != nil && !reflect.ValueOf(v).Comparable() {
+			return false
+		}
+	}
+
+	return a == b
+}
+
+// View calls fn with the wrapped map while holding the read lock, so every lookup fn makes sees the same state.
+// fn may type-assert the map to its concrete type for ordered queries such as Range or Walk,
+// but must not change it or call other methods of the ConcurrentMap.
+func (c *ConcurrentMap) View(fn func(m OrderedReader)) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	fn(c.m)
+}
+
+// Batch calls fn with a Tx while holding the write lock, so the changes fn makes through the Tx
+// are applied atomically: no other operation sees some of them without the rest.
+// If fn returns an error or panics, every change it made is undone before Batch returns the error
+// or the panic continues. fn must not keep the Tx or call other methods of the ConcurrentMap.
+func (c *ConcurrentMap) Batch(fn func(tx *Tx) error) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	tx := &Tx{m: c.m}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.rollback()
+		}
+	}()
+	if err = fn(tx); err != nil {
+		return err
+	}
+	committed = true
+
+	return nil
+}
+
+// Tx is the view of a ConcurrentMap's entries inside Batch.
+// It records how to undo each change, so the batch can be rolled back.
+type Tx struct {
+	m    OrderedMap // the wrapped map, locked for writing
+	undo []func()   // the inverse of every change so far, in order
+}
+
+// Search takes a key and returns a boolean stating whether the key was found or not.
+func (tx *Tx) Search(key interface{}) bool {
+	return tx.m.Search(key)
+}
+
+// ReturnNodeValue takes a key and returns the value associated with the key or an error, if there was one.
+func (tx *Tx) ReturnNodeValue(key interface{}) (interface{}, error) {
+	return tx.m.ReturnNodeValue(key)
+}
+
+// Size returns the number of entries in the map.
+func (tx *Tx) Size() int {
+	return tx.m.Size()
+}
+
+// IsEmpty returns a boolean stating whether the map is empty or not.
+func (tx *Tx) IsEmpty() bool {
+	return tx.m.IsEmpty()
+}
+
+// Insert takes a key and a value and stores them as a new entry.
+// It returns the inserted key or an error if the key already exists.
+func (tx *Tx) Insert(key, value interface{}) (interface{}, error) {
+	inserted, err := tx.m.Insert(key, value)
+	if err == nil {
+		tx.undo = append(tx.undo, func() { tx.m.Delete(key) })
+	}
+
+	return inserted, err
+}
+
+// Update takes a key and a value and replaces the value of an existing entry.
+// It returns the new value or an error if the key does not exist.
+func (tx *Tx) Update(key, value interface{}) (interface{}, error) {
+	old, err := tx.m.ReturnNodeValue(key)
+	if err != nil {
+		return nil, err
+	}
+	tx.undo = append(tx.undo, func() { tx.m.Update(key, old) })
+
+	return tx.m.Update(key, value)
+}
+
+// Delete takes a key and removes its entry.
+// It returns the deleted key or an error if the key does not exist.
+func (tx *Tx) Delete(key interface{}) (interface{}, error) {
+	old, err := tx.m.ReturnNodeValue(key)
+	if err != nil {
+		return nil, err
+	}
+	deleted, err := tx.m.Delete(key)
+	tx.undo = append(tx.undo, func() { tx.m.Insert(deleted, old) })
+
+	return deleted, err
+}
+
+// rollback undoes every change of the transaction, latest first.
+func (tx *Tx) rollback() {
+	for i := len(tx.undo) - 1; i >= 0; i-- {
+		tx.undo[i]()
+	}
+	tx.undo = nil
+}
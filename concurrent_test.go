@@ -0,0 +1,287 @@
+package trees_test
+
+import (
+	"errors"
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/avl"
+	"github.com/chancetudor/trees/bst"
+	"github.com/chancetudor/trees/rbt"
+	"github.com/chancetudor/trees/treetest"
+	"sync"
+	"testing"
+)
+
+// These tests are meant to be run with the race detector as well:
+//
+//	go test -race -run Concurrent .
+
+// concurrentMaps lists the trees the tests wrap.
+var concurrentMaps = []struct {
+	name string
+	new  func() trees.OrderedMap
+}{
+	{"bst", func() trees.OrderedMap { return bst.NewWithIntComparator() }},
+	{"avl", func() trees.OrderedMap { return avl.NewWithIntComparator() }},
+	{"rbt", func() trees.OrderedMap { return rbt.NewWithIntComparator() }},
+}
+
+func TestConcurrentMap_Conformance(t *testing.T) {
+	for _, m := range concurrentMaps {
+		newMap := func() trees.OrderedMap { return trees.NewConcurrent(m.new()) }
+		t.Run(m.name, func(t *testing.T) {
+			treetest.TestOrderedMap(t, newMap)
+			treetest.TestModel(t, newMap, nil)
+		})
+	}
+}
+
+func TestConcurrentMap_Parallel(t *testing.T) {
+	const writers, perWriter = 8, 500
+	for _, m := range concurrentMaps {
+		t.Run(m.name, func(t *testing.T) {
+			c := trees.NewConcurrent(m.new())
+			var wg sync.WaitGroup
+			// each writer owns the keys w, w+writers, w+2*writers, ...: it inserts them all,
+			// updates them, and deletes every other one, while readers look them up
+			for w := 0; w < writers; w++ {
+				wg.Add(2)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < perWriter; i++ {
+						key := w + i*writers
+						if _, err := c.Insert(key, 0); err != nil {
+							t.Errorf("Insert(%d) error = %v", key, err)
+						}
+						if _, err := c.Update(key, key); err != nil {
+							t.Errorf("Update(%d) error = %v", key, err)
+						}
+						if i%2 == 1 {
+							if _, err := c.Delete(key); err != nil {
+								t.Errorf("Delete(%d) error = %v", key, err)
+							}
+						}
+					}
+				}(w)
+				go func(w int) {
+					defer wg.Done()
+					for i := 0; i < perWriter; i++ {
+						key := w + i*writers
+						// a key is missing, freshly inserted with 0, or updated to itself
+						if value, err := c.ReturnNodeValue(key); err == nil && value != 0 && value != key {
+							t.Errorf("ReturnNodeValue(%d) = %v", key, value)
+						}
+						c.Size()
+					}
+				}(w)
+			}
+			wg.Wait()
+
+			if c.Size() != writers*perWriter/2 {
+				t.Fatalf("Size() = %d, want %d", c.Size(), writers*perWriter/2)
+			}
+			for key := 0; key < writers*perWriter; key++ {
+				value, err := c.ReturnNodeValue(key)
+				if deleted := (key/writers)%2 == 1; deleted != (err != nil) || (!deleted && value != key) {
+					t.Fatalf("ReturnNodeValue(%d) = %v, %v", key, value, err)
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentMap_CompareAndSwap(t *testing.T) {
+	const goroutines, increments, counter, missing = 8, 300, 0, 1
+	c := trees.NewConcurrent(avl.NewWithIntComparator())
+	c.Insert(counter, 0)
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; {
+				old, _ := c.ReturnNodeValue(counter)
+				if c.CompareAndSwap(counter, old, old.(int)+1) {
+					i++
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if value, _ := c.ReturnNodeValue(counter); value != goroutines*increments {
+		t.Errorf("counter = %v, want %d", value, goroutines*increments)
+	}
+
+	if c.CompareAndSwap(counter, -1, 0) || c.CompareAndSwap(missing, nil, 0) {
+		t.Errorf("CompareAndSwap() swapped a mismatched or missing entry")
+	}
+	if c.CompareAndDelete(counter, -1) || !c.CompareAndDelete(counter, goroutines*increments) || c.Search(counter) {
+		t.Errorf("CompareAndDelete() did not delete exactly when the value matched")
+	}
+}
+
+func TestConcurrentMap_CompareNonComparable(t *testing.T) {
+	const slice, boxed = 0, 1
+	c := trees.NewConcurrent(avl.NewWithIntComparator())
+	c.Insert(slice, []int{1})
+	// a comparable type holding a slice, which only the dynamic check catches
+	c.Insert(boxed, struct{ v interface{} }{[]int{1}})
+	for _, key := range []int{slice, boxed} {
+		old, _ := c.ReturnNodeValue(key)
+		if c.CompareAndSwap(key, old, nil) || c.CompareAndDelete(key, old) {
+			t.Errorf("key %d: a non-comparable value compared equal", key)
+		}
+	}
+	if c.Size() != 2 {
+		t.Errorf("Size() = %d, want 2", c.Size())
+	}
+}
+
+func TestConcurrentMap_LoadOrStore(t *testing.T) {
+	const goroutines = 16
+	c := trees.NewConcurrent(rbt.NewWithIntComparator())
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	stored := 0
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			actual, loaded := c.LoadOrStore(1, g)
+			mu.Lock()
+			defer mu.Unlock()
+			if !loaded {
+				stored++
+				if actual != g {
+					t.Errorf("LoadOrStore() stored %v, want %d", actual, g)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if stored != 1 || c.Size() != 1 {
+		t.Errorf("%d goroutines stored a value and Size() = %d, want 1 and 1", stored, c.Size())
+	}
+
+	winner, _ := c.ReturnNodeValue(1)
+	if value, loaded := c.LoadAndDelete(1); !loaded || value != winner || c.Search(1) {
+		t.Errorf("LoadAndDelete(1) = %v, %v, want %v, true", value, loaded, winner)
+	}
+	if _, loaded := c.LoadAndDelete(1); loaded {
+		t.Errorf("LoadAndDelete() of a missing key loaded a value")
+	}
+}
+
+func TestConcurrentMap_Batch(t *testing.T) {
+	const accounts, balance, transfers = 10, 100, 200
+	c := trees.NewConcurrent(rbt.NewWithIntComparator())
+	for a := 0; a < accounts; a++ {
+		c.Insert(a, balance)
+	}
+	errOverdrawn := errors.New("overdrawn")
+	transfer := func(from, to, amount int) error {
+		return c.Batch(func(tx *trees.Tx) error {
+			fromBalance, _ := tx.ReturnNodeValue(from)
+			toBalance, _ := tx.ReturnNodeValue(to)
+			tx.Update(from, fromBalance.(int)-amount)
+			if fromBalance.(int) < amount {
+				return errOverdrawn // undoes the Update above
+			}
+			_, err := tx.Update(to, toBalance.(int)+amount)
+			return err
+		})
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < transfers; i++ {
+				if err := transfer((g+i)%accounts, (g+3*i+1)%accounts, i%150); err != nil && err != errOverdrawn {
+					t.Errorf("transfer error = %v", err)
+				}
+			}
+		}(g)
+		// readers never see money in flight
+		go func() {
+			defer wg.Done()
+			for i := 0; i < transfers; i++ {
+				c.View(func(m trees.OrderedReader) {
+					total := 0
+					for a := 0; a < accounts; a++ {
+						value, _ := m.ReturnNodeValue(a)
+						if value.(int) < 0 {
+							t.Errorf("account %d is overdrawn: %v", a, value)
+						}
+						total += value.(int)
+					}
+					if total != accounts*balance {
+						t.Errorf("total = %d, want %d", total, accounts*balance)
+					}
+				})
+			}
+		}()
+	}
+	wg.Wait()
+	if c.Size() != accounts {
+		t.Errorf("Size() = %d, want %d", c.Size(), accounts)
+	}
+}
+
+func TestConcurrentMap_BatchRollback(t *testing.T) {
+	c := trees.NewConcurrent(avl.NewWithIntComparator())
+	c.Insert(1, "one")
+	c.Insert(2, "two")
+	changes := func(tx *trees.Tx) {
+		tx.Insert(3, "three")
+		tx.Update(1, "uno")
+		tx.Delete(2)
+		tx.Insert(2, "dos")
+		tx.Delete(3)
+	}
+	unchanged := func(when string) {
+		for key, want := range map[int]string{1: "one", 2: "two"} {
+			if value, err := c.ReturnNodeValue(key); err != nil || value != want {
+				t.Errorf("%s: ReturnNodeValue(%d) = %v, %v, want %q", when, key, value, err, want)
+			}
+		}
+		if c.Size() != 2 {
+			t.Errorf("%s: Size() = %d, want 2", when, c.Size())
+		}
+	}
+
+	failure := errors.New("failure")
+	if err := c.Batch(func(tx *trees.Tx) error {
+		changes(tx)
+		if tx.Size() != 2 || !tx.Search(2) || tx.IsEmpty() {
+			t.Errorf("the Tx does not see its own changes")
+		}
+		return failure
+	}); err != failure {
+		t.Errorf("Batch() error = %v, want %v", err, failure)
+	}
+	unchanged("after an error")
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recover() = %v, want the batch's panic", r)
+			}
+		}()
+		c.Batch(func(tx *trees.Tx) error {
+			changes(tx)
+			panic("boom")
+		})
+	}()
+	unchanged("after a panic")
+
+	if err := c.Batch(func(tx *trees.Tx) error {
+		changes(tx)
+		return nil
+	}); err != nil {
+		t.Fatalf("Batch() error = %v", err)
+	}
+	if one, _ := c.ReturnNodeValue(1); one != "uno" || c.Search(3) || c.Size() != 2 {
+		t.Errorf("a committed batch was not applied")
+	}
+}
@@ -0,0 +1,14 @@
+package trees
+
+// DOTOptions configures the WriteDOT methods of bst.BST, avl.AVL, and rbt.RBT,
+// which export a tree's structure in the Graphviz DOT language. The zero DOTOptions is ready to use.
+type DOTOptions struct {
+	// Name is the name of the graph. If empty, "tree" is used.
+	Name string
+	// NilLeaves draws the nil children of every node as points,
+	// which also pins a lone child to its side of the parent.
+	NilLeaves bool
+	// Label takes a node's key and value and returns the text drawn in the node.
+	// If nil, the key is converted to a string.
+	Label func(key, value interface{}) string
+}
@@ -1,5 +1,5 @@
 module github.com/chancetudor/trees
 
-go 1.16
+go 1.21
 
 require github.com/emirpasic/gods v1.12.0
@@ -0,0 +1,303 @@
+// Package bench defines the benchmark suite that compares bst.BST, avl.AVL, and rbt.RBT
+// with a Go map and the red-black tree from gods. The benchmarks in this package's tests
+// and the cmd/treebench tool both run the Cases it returns.
+package bench
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/avl"
+	"github.com/chancetudor/trees/bst"
+	"github.com/chancetudor/trees/rbt"
+	"github.com/emirpasic/gods/trees/redblacktree"
+	"math/rand"
+	"testing"
+)
+
+// Map is the small interface every benchmarked implementation is adapted to.
+type Map interface {
+	Insert(key, value int)
+	Search(key int) bool
+	Delete(key int)
+	// Iterate calls fn for every entry until fn returns false, in key order if the implementation is ordered.
+	Iterate(fn func(key, value int) bool)
+}
+
+// Impl is a benchmarked implementation.
+type Impl struct {
+	Name string
+	New  func() Map
+	// Balanced reports whether the implementation stays O(log n) on any insertion order.
+	// Unbalanced implementations are skipped on degenerate workloads at large sizes.
+	Balanced bool
+}
+
+// Impls lists the benchmarked implementations.
+var Impls = []Impl{
+	{Name: "bst", New: func() Map { return orderedMap{bst.NewWithIntComparator()} }},
+	{Name: "avl", New: func() Map { return orderedMap{avl.NewWithIntComparator()} }, Balanced: true},
+	{Name: "rbt", New: func() Map { return orderedMap{rbt.NewWithIntComparator()} }, Balanced: true},
+	{Name: "gomap", New: func() Map { return goMap{} }, Balanced: true},
+	{Name: "gods-rbt", New: func() Map { return godsMap{redblacktree.NewWithIntComparator()} }, Balanced: true},
+}
+
+// walkable is the surface shared by the trees of this module that the benchmarks use.
+type walkable interface {
+	trees.OrderedMap
+	Walk(order trees.TraversalOrder, fn func(key, value interface{}) bool)
+}
+
+// orderedMap adapts bst.BST, avl.AVL, and rbt.RBT to Map.
+type orderedMap struct {
+	tree walkable
+}
+
+func (m orderedMap) Insert(key, value int) {
+	m.tree.Insert(key, value)
+}
+
+func (m orderedMap) Search(key int) bool {
+	return m.tree.Search(key)
+}
+
+func (m orderedMap) Delete(key int) {
+	m.tree.Delete(key)
+}
+
+func (m orderedMap) Iterate(fn func(key, value int) bool) {
+	m.tree.Walk(trees.InOrder, func(key, value interface{}) bool {
+		return fn(key.(int), value.(int))
+	})
+}
+
+// goMap adapts a Go map to Map. It iterates in random order.
+type goMap map[int]int
+
+func (m goMap) Insert(key, value int) {
+	m[key] = value
+}
+
+func (m goMap) Search(key int) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func (m goMap) Delete(key int) {
+	delete(m, key)
+}
+
+func (m goMap) Iterate(fn func(key, value int) bool) {
+	for key, value := range m {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// godsMap adapts the gods red-black tree to Map.
+type godsMap struct {
+	tree *redblacktree.Tree
+}
+
+func (m godsMap) Insert(key, value int) {
+	m.tree.Put(key, value)
+}
+
+func (m godsMap) Search(key int) bool {
+	_, found := m.tree.Get(key)
+	return found
+}
+
+func (m godsMap) Delete(key int) {
+	m.tree.Remove(key)
+}
+
+func (m godsMap) Iterate(fn func(key, value int) bool) {
+	for it := m.tree.Iterator(); it.Next(); {
+		if !fn(it.Key().(int), it.Value().(int)) {
+			return
+		}
+	}
+}
+
+// Workload is a key distribution. Insert is the order n keys are inserted in,
+// Access the order they are searched for, and Delete the order they are deleted in.
+type Workload struct {
+	Name   string
+	Insert func(n int) []int
+	Access func(n int) []int
+	Delete func(n int) []int
+	// Degenerate reports whether the insertion order makes an unbalanced tree a linked list.
+	Degenerate bool
+}
+
+// Workloads lists the benchmarked key distributions.
+var Workloads = []Workload{
+	{Name: "sequential", Insert: ascending, Access: ascending, Delete: ascending, Degenerate: true},
+	{Name: "random", Insert: permutation(1), Access: permutation(2), Delete: permutation(3)},
+	{Name: "zipfian", Insert: permutation(1), Access: zipfian, Delete: permutation(3)},
+	{Name: "zigzag", Insert: zigzag, Access: zigzag, Delete: zigzag, Degenerate: true},
+}
+
+// ascending returns the keys 0 to n-1 in order.
+func ascending(n int) []int {
+	keys := make([]int, n)
+	for i := range keys {
+		keys[i] = i
+	}
+
+	return keys
+}
+
+// permutation returns a function that returns the keys 0 to n-1 shuffled by seed.
+func permutation(seed int64) func(n int) []int {
+	return func(n int) []int {
+		return rand.New(rand.NewSource(seed)).Perm(n)
+	}
+}
+
+// zipfian returns n keys between 0 and n-1 drawn from a Zipf distribution, so a few hot keys dominate,
+// scattered over the key space so the hot keys are not all adjacent.
+func zipfian(n int) []int {
+	r := rand.New(rand.NewSource(4))
+	zipf := rand.NewZipf(r, 1.1, 1, uint64(n-1))
+	scatter := r.Perm(n)
+	keys := make([]int, n)
+	for i := range keys {
+		keys[i] = scatter[zipf.Uint64()]
+	}
+
+	return keys
+}
+
+// zigzag returns the keys 0 to n-1 alternately from the outside in: 0, n-1, 1, n-2, and so on.
+// Every insert lands at the deepest point of an unbalanced tree.
+func zigzag(n int) []int {
+	keys := make([]int, 0, n)
+	for lo, hi := 0, n-1; lo <= hi; lo, hi = lo+1, hi-1 {
+		keys = append(keys, lo)
+		if lo != hi {
+			keys = append(keys, hi)
+		}
+	}
+
+	return keys
+}
+
+// Ops lists the benchmarked operations.
+var Ops = []string{"Insert", "Search", "Delete", "Iterate"}
+
+// DefaultSizes are the map sizes benchmarked by default.
+var DefaultSizes = []int{1000, 10000, 100000}
+
+// maxDegenerate is the largest size an unbalanced implementation is benchmarked at on a degenerate workload,
+// where each operation costs O(n).
+const maxDegenerate = 10000
+
+// Case is one benchmark: an operation on one implementation, workload, and size.
+// Every op of Run is one call: one Insert, Search, or Delete, or one step of an iteration.
+type Case struct {
+	Op       string
+	Impl     string
+	Workload string
+	Size     int
+	Run      func(b *testing.B)
+}
+
+// Name returns the case's name in the form Op/workload/n=size/impl, as used for sub-benchmarks.
+func (c Case) Name() string {
+	return fmt.Sprintf("%s/%s/n=%d/%s", c.Op, c.Workload, c.Size, c.Impl)
+}
+
+// Cases returns every combination of Ops, Workloads, sizes, and Impls,
+// except unbalanced implementations on degenerate workloads above maxDegenerate.
+func Cases(sizes []int) []Case {
+	var cases []Case
+	for _, op := range Ops {
+		for _, w := range Workloads {
+			for _, n := range sizes {
+				for _, impl := range Impls {
+					if w.Degenerate && !impl.Balanced && n > maxDegenerate {
+						continue
+					}
+					cases = append(cases, Case{
+						Op:       op,
+						Impl:     impl.Name,
+						Workload: w.Name,
+						Size:     n,
+						Run:      run(op, impl, w, n),
+					})
+				}
+			}
+		}
+	}
+
+	return cases
+}
+
+// fill returns a new map from impl holding keys, each mapped to itself.
+func fill(impl Impl, keys []int) Map {
+	m := impl.New()
+	for _, key := range keys {
+		m.Insert(key, key)
+	}
+
+	return m
+}
+
+// run returns the benchmark function of one case.
+// Keys are generated when the benchmark starts, so listing the cases stays cheap.
+func run(op string, impl Impl, w Workload, n int) func(b *testing.B) {
+	switch op {
+	case "Insert":
+		return func(b *testing.B) {
+			inserts := w.Insert(n)
+			b.ReportAllocs()
+			var m Map
+			for i := 0; i < b.N; i++ {
+				if i%n == 0 {
+					b.StopTimer()
+					m = impl.New()
+					b.StartTimer()
+				}
+				m.Insert(inserts[i%n], i)
+			}
+		}
+	case "Search":
+		return func(b *testing.B) {
+			accesses := w.Access(n)
+			m := fill(impl, w.Insert(n))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				m.Search(accesses[i%n])
+			}
+		}
+	case "Delete":
+		return func(b *testing.B) {
+			inserts, deletes := w.Insert(n), w.Delete(n)
+			b.ReportAllocs()
+			var m Map
+			for i := 0; i < b.N; i++ {
+				if i%n == 0 {
+					b.StopTimer()
+					m = fill(impl, inserts)
+					b.StartTimer()
+				}
+				m.Delete(deletes[i%n])
+			}
+		}
+	default:
+		return func(b *testing.B) {
+			m := fill(impl, w.Insert(n))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for done := 0; done < b.N; {
+				m.Iterate(func(key, value int) bool {
+					done++
+					return done < b.N
+				})
+			}
+		}
+	}
+}
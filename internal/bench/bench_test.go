@@ -0,0 +1,91 @@
+package bench
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// BenchmarkTrees runs every case. Narrow it with -bench, for example
+// go test ./internal/bench -bench 'Trees/Search/random/n=10000/'
+func BenchmarkTrees(b *testing.B) {
+	for _, c := range Cases(DefaultSizes) {
+		b.Run(c.Name(), c.Run)
+	}
+}
+
+func TestWorkloads(t *testing.T) {
+	const n = 1001
+	for _, w := range Workloads {
+		for name, keys := range map[string][]int{"insert": w.Insert(n), "delete": w.Delete(n)} {
+			sorted := append([]int(nil), keys...)
+			sort.Ints(sorted)
+			for i, key := range sorted {
+				if key != i {
+					t.Fatalf("%s %s keys are not a permutation of 0 to %d", w.Name, name, n-1)
+				}
+			}
+		}
+		for _, key := range w.Access(n) {
+			if key < 0 || key >= n {
+				t.Fatalf("%s access key %d is out of range", w.Name, key)
+			}
+		}
+	}
+}
+
+func TestImpls(t *testing.T) {
+	for _, impl := range Impls {
+		m := fill(impl, zigzag(100))
+		for key := 0; key < 100; key += 2 {
+			m.Delete(key)
+		}
+		count := 0
+		m.Iterate(func(key, value int) bool {
+			if key%2 == 0 || key != value {
+				t.Errorf("%s iterated %d: %d after deletes", impl.Name, key, value)
+			}
+			count++
+			return true
+		})
+		if count != 50 || !m.Search(99) || m.Search(98) {
+			t.Errorf("%s holds %d entries, want 50", impl.Name, count)
+		}
+	}
+}
+
+func TestCases(t *testing.T) {
+	for _, c := range Cases([]int{10, 20000}) {
+		if c.Impl == "bst" && c.Size > maxDegenerate && (c.Workload == "sequential" || c.Workload == "zigzag") {
+			t.Errorf("Cases() includes %s", c.Name())
+		}
+	}
+}
+
+func TestReports(t *testing.T) {
+	results := []Result{
+		{Case: Case{Op: "Search", Workload: "random", Size: 10, Impl: "avl"}, NsPerOp: 30, BytesPerOp: 8, AllocsPerOp: 1},
+		{Case: Case{Op: "Search", Workload: "random", Size: 10, Impl: "rbt"}, NsPerOp: 20},
+		{Case: Case{Op: "Insert", Workload: "random", Size: 10, Impl: "rbt"}, NsPerOp: 50},
+	}
+	setRelative(results)
+	var csvOut, mdOut strings.Builder
+	if err := WriteCSV(&csvOut, results); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+	wantCSV := "op,workload,n,impl,ns/op,B/op,allocs/op,vs fastest\n" +
+		"Search,random,10,avl,30.0,8,1,1.50x\n" +
+		"Search,random,10,rbt,20.0,0,0,1.00x\n" +
+		"Insert,random,10,rbt,50.0,0,0,1.00x\n"
+	if csvOut.String() != wantCSV {
+		t.Errorf("WriteCSV() =\n%s\nwant\n%s", csvOut.String(), wantCSV)
+	}
+	if err := WriteMarkdown(&mdOut, results); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(mdOut.String(), "\n"), "\n")
+	if len(lines) != 5 || lines[1] != "| --- | --- | --- | --- | ---: | ---: | ---: | ---: |" ||
+		lines[2] != "| Search | random | 10 | avl | 30.0 | 8 | 1 | 1.50x |" {
+		t.Errorf("WriteMarkdown() =\n%s", mdOut.String())
+	}
+}
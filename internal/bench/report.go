@@ -0,0 +1,115 @@
+package bench
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"testing"
+)
+
+// Result is the measurement of one Case.
+type Result struct {
+	Case
+	NsPerOp     float64
+	BytesPerOp  int64
+	AllocsPerOp int64
+	// Relative is NsPerOp divided by the fastest NsPerOp among the results
+	// with the same operation, workload, and size.
+	Relative float64
+}
+
+// Measure runs every case with testing.Benchmark, calling progress, if not nil, before each one,
+// and returns the results with Relative filled in.
+func Measure(cases []Case, progress func(c Case)) []Result {
+	results := make([]Result, 0, len(cases))
+	for _, c := range cases {
+		if progress != nil {
+			progress(c)
+		}
+		r := testing.Benchmark(c.Run)
+		results = append(results, Result{
+			Case:        c,
+			NsPerOp:     float64(r.T.Nanoseconds()) / float64(r.N),
+			BytesPerOp:  r.AllocedBytesPerOp(),
+			AllocsPerOp: r.AllocsPerOp(),
+		})
+	}
+	setRelative(results)
+
+	return results
+}
+
+// setRelative fills in the Relative field of every result.
+func setRelative(results []Result) {
+	type group struct {
+		op, workload string
+		size         int
+	}
+	fastest := make(map[group]float64)
+	for _, r := range results {
+		g := group{r.Op, r.Workload, r.Size}
+		if best, ok := fastest[g]; !ok || r.NsPerOp < best {
+			fastest[g] = r.NsPerOp
+		}
+	}
+	for i, r := range results {
+		if best := fastest[group{r.Op, r.Workload, r.Size}]; best > 0 {
+			results[i].Relative = r.NsPerOp / best
+		}
+	}
+}
+
+// columns are the headers of both report formats.
+var columns = []string{"op", "workload", "n", "impl", "ns/op", "B/op", "allocs/op", "vs fastest"}
+
+// row returns the cells of a result in the order of columns.
+func (r Result) row() []string {
+	return []string{
+		r.Op,
+		r.Workload,
+		strconv.Itoa(r.Size),
+		r.Impl,
+		strconv.FormatFloat(r.NsPerOp, 'f', 1, 64),
+		strconv.FormatInt(r.BytesPerOp, 10),
+		strconv.FormatInt(r.AllocsPerOp, 10),
+		strconv.FormatFloat(r.Relative, 'f', 2, 64) + "x",
+	}
+}
+
+// WriteCSV writes the results to w as CSV with a header row.
+func WriteCSV(w io.Writer, results []Result) error {
+	out := csv.NewWriter(w)
+	out.Write(columns)
+	for _, r := range results {
+		out.Write(r.row())
+	}
+	out.Flush()
+
+	return out.Error()
+}
+
+// WriteMarkdown writes the results to w as a Markdown table.
+func WriteMarkdown(w io.Writer, results []Result) error {
+	line := func(cells []string) string {
+		s := "|"
+		for _, cell := range cells {
+			s += " " + cell + " |"
+		}
+		return s + "\n"
+	}
+	separator := make([]string, len(columns))
+	for i := range separator {
+		separator[i] = "---"
+		if i >= 4 {
+			separator[i] = "---:"
+		}
+	}
+	text := line(columns) + line(separator)
+	for _, r := range results {
+		text += line(r.row())
+	}
+	_, err := fmt.Fprint(w, text)
+
+	return err
+}
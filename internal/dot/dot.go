@@ -0,0 +1,33 @@
+// Package dot holds the Graphviz DOT helpers shared by the WriteDOT methods of bst, avl, and rbt,
+// so that only trees.DOTOptions is part of the public API.
+package dot
+
+import (
+	"github.com/chancetudor/trees"
+	"github.com/emirpasic/gods/utils"
+	"strings"
+)
+
+// GraphName returns the graph name from opts, quoted for use in a DOT file.
+func GraphName(opts trees.DOTOptions) string {
+	if opts.Name == "" {
+		return `"tree"`
+	}
+
+	return `"` + Escape(opts.Name) + `"`
+}
+
+// NodeLabel takes a node's key and value and returns its label from opts, escaped for use in a DOT string.
+func NodeLabel(opts trees.DOTOptions, key, value interface{}) string {
+	if opts.Label == nil {
+		return Escape(utils.ToString(key))
+	}
+
+	return Escape(opts.Label(key, value))
+}
+
+// Escape takes text and escapes the backslashes, double quotes, and newlines in it
+// so that it can be placed between double quotes in a DOT file.
+func Escape(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(text)
+}
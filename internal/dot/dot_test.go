@@ -0,0 +1,28 @@
+package dot
+
+import (
+	"github.com/chancetudor/trees"
+	"testing"
+)
+
+func TestEscape(t *testing.T) {
+	if got, want := Escape("a\\b\"c\nd"), `a\\b\"c\nd`; got != want {
+		t.Errorf("Escape() = %s, want %s", got, want)
+	}
+}
+
+func TestGraphNameAndNodeLabel(t *testing.T) {
+	if got := GraphName(trees.DOTOptions{}); got != `"tree"` {
+		t.Errorf("GraphName() = %s, want \"tree\"", got)
+	}
+	if got, want := GraphName(trees.DOTOptions{Name: `my "graph"`}), `"my \"graph\""`; got != want {
+		t.Errorf("GraphName() = %s, want %s", got, want)
+	}
+	if got := NodeLabel(trees.DOTOptions{}, 7, "seven"); got != "7" {
+		t.Errorf("NodeLabel() = %s, want 7", got)
+	}
+	opts := trees.DOTOptions{Label: func(key, value interface{}) string { return value.(string) }}
+	if got := NodeLabel(opts, 7, `"seven"`); got != `\"seven\"` {
+		t.Errorf("NodeLabel() = %s, want \\\"seven\\\"", got)
+	}
+}
@@ -0,0 +1,128 @@
+// Package jsonpairs encodes the entries of an ordered map as a JSON array of {"key", "value"} objects,
+// which keeps their order, unlike a JSON object. bst, avl, and rbt share it for their JSON methods.
+package jsonpairs
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/emirpasic/gods/utils"
+	"reflect"
+	"sort"
+)
+
+// pair is one encoded entry.
+type pair struct {
+	Key   interface{} `json:"key"`
+	Value interface{} `json:"value"`
+}
+
+// rawPair is one entry whose key and value have not been decoded yet.
+type rawPair struct {
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Marshal takes a function that calls fn for every entry in order and returns the entries as a JSON array.
+// An empty map is encoded as [], not null.
+func Marshal(walk func(fn func(key, value interface{}) bool)) ([]byte, error) {
+	pairs := []pair{}
+	walk(func(key, value interface{}) bool {
+		pairs = append(pairs, pair{Key: key, Value: value})
+		return true
+	})
+
+	return json.Marshal(pairs)
+}
+
+// Unmarshal decodes a JSON array of entries and returns their keys and values, sorted by comparator.
+// Keys are decoded into new values of keyType and values into new values of valueType;
+// a nil type decodes as encoding/json does into an interface{}, so numbers become float64.
+// A null value decodes as the zero value of valueType. If the comparator panics on the decoded keys,
+// as utils.IntComparator does on a float64, Unmarshal returns an error instead.
+// Input that is already sorted is not re-sorted, so decoding the output of Marshal takes O(n).
+func Unmarshal(data []byte, comparator utils.Comparator, keyType, valueType reflect.Type) (keys, values []interface{}, err error) {
+	var raw []rawPair
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, nil, err
+	}
+	keys, values = make([]interface{}, len(raw)), make([]interface{}, len(raw))
+	for i, p := range raw {
+		if p.Key == nil {
+			return nil, nil, fmt.Errorf("JSON ERROR: entry %d has no key", i)
+		}
+		if keys[i], err = decode(p.Key, keyType); err != nil {
+			return nil, nil, fmt.Errorf("JSON ERROR: key of entry %d: %w", i, err)
+		}
+		if p.Value == nil || string(p.Value) == "null" {
+			if valueType != nil {
+				values[i] = reflect.Zero(valueType).Interface()
+			}
+			continue
+		}
+		if values[i], err = decode(p.Value, valueType); err != nil {
+			return nil, nil, fmt.Errorf("JSON ERROR: value of entry %d: %w", i, err)
+		}
+	}
+	if err := sortByKey(keys, values, comparator); err != nil {
+		return nil, nil, err
+	}
+
+	return keys, values, nil
+}
+
+// sortByKey sorts keys, and values along with them, by comparator, unless they are already sorted.
+// A panic in the comparator, such as a failed type assertion on a key of the wrong type, is returned as an error.
+func sortByKey(keys, values []interface{}, comparator utils.Comparator) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("JSON ERROR: the comparator cannot compare the decoded keys (call SetJSONTypes to choose the key type): %v", r)
+		}
+	}()
+	// sorting compares no keys when there is only one, so compare each key with itself first
+	for _, key := range keys {
+		comparator(key, key)
+	}
+	sorted := sort.SliceIsSorted(keys, func(i, j int) bool {
+		return comparator(keys[i], keys[j]) < 0
+	})
+	if !sorted {
+		sort.Stable(byKey{keys: keys, values: values, comparator: comparator})
+	}
+
+	return nil
+}
+
+// decode takes a JSON value and decodes it into a new value of type t, or into an interface{} if t is nil.
+func decode(data json.RawMessage, t reflect.Type) (interface{}, error) {
+	if t == nil {
+		var v interface{}
+		err := json.Unmarshal(data, &v)
+		return v, err
+	}
+	v := reflect.New(t)
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return nil, err
+	}
+
+	return v.Elem().Interface(), nil
+}
+
+// byKey sorts keys, and values along with them, by comparator.
+type byKey struct {
+	keys       []interface{}
+	values     []interface{}
+	comparator utils.Comparator
+}
+
+func (b byKey) Len() int {
+	return len(b.keys)
+}
+
+func (b byKey) Less(i, j int) bool {
+	return b.comparator(b.keys[i], b.keys[j]) < 0
+}
+
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+}
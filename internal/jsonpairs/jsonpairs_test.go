@@ -0,0 +1,51 @@
+package jsonpairs
+
+import (
+	"github.com/emirpasic/gods/utils"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnmarshal_WrongKeyType(t *testing.T) {
+	// without a key type, 1 and 2 decode as float64, which utils.IntComparator panics on
+	data := []byte(`[{"key":1,"value":"a"},{"key":2,"value":"b"}]`)
+	_, _, err := Unmarshal(data, utils.IntComparator, nil, nil)
+	if err == nil || !strings.Contains(err.Error(), "JSON ERROR") {
+		t.Fatalf("Unmarshal() error = %v, want a JSON ERROR", err)
+	}
+	// a single key is never compared with another, but must still be caught
+	if _, _, err := Unmarshal([]byte(`[{"key":1,"value":"x"}]`), utils.IntComparator, nil, nil); err == nil {
+		t.Errorf("Unmarshal() of one float64 key returned no error")
+	}
+	keys, values, err := Unmarshal(data, utils.IntComparator, reflect.TypeOf(0), reflect.TypeOf(""))
+	if err != nil || !reflect.DeepEqual(keys, []interface{}{1, 2}) || !reflect.DeepEqual(values, []interface{}{"a", "b"}) {
+		t.Errorf("Unmarshal() = %v, %v, %v", keys, values, err)
+	}
+}
+
+func TestUnmarshal_NullValue(t *testing.T) {
+	data := []byte(`[{"key":2,"value":null},{"key":1}]`)
+	keys, values, err := Unmarshal(data, utils.IntComparator, reflect.TypeOf(0), reflect.TypeOf(0))
+	if err != nil || !reflect.DeepEqual(keys, []interface{}{1, 2}) || !reflect.DeepEqual(values, []interface{}{0, 0}) {
+		t.Errorf("Unmarshal() with a value type = %v, %v, %v, want zero values", keys, values, err)
+	}
+	_, values, err = Unmarshal(data, utils.IntComparator, reflect.TypeOf(0), nil)
+	if err != nil || !reflect.DeepEqual(values, []interface{}{nil, nil}) {
+		t.Errorf("Unmarshal() without a value type = %v, %v, want nil values", values, err)
+	}
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	data, err := Marshal(func(fn func(key, value interface{}) bool) {
+		fn("a", 1)
+		fn("b", nil)
+	})
+	if err != nil || string(data) != `[{"key":"a","value":1},{"key":"b","value":null}]` {
+		t.Fatalf("Marshal() = %s, %v", data, err)
+	}
+	keys, values, err := Unmarshal(data, utils.StringComparator, reflect.TypeOf(""), reflect.TypeOf(0))
+	if err != nil || !reflect.DeepEqual(keys, []interface{}{"a", "b"}) || !reflect.DeepEqual(values, []interface{}{1, 0}) {
+		t.Errorf("Unmarshal() = %v, %v, %v", keys, values, err)
+	}
+}
@@ -0,0 +1,180 @@
+// Package render draws binary trees as text with box-drawing characters.
+// bst, avl, and rbt copy their trees into Nodes and render those,
+// so the layout code is shared instead of repeated in every package.
+package render
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Node is a labeled binary tree node.
+type Node struct {
+	Label string
+	Left  *Node
+	Right *Node
+}
+
+// Sideways takes the root of a tree and returns the tree drawn sideways,
+// one node per line, with the root at the left and the right subtree above the left one.
+// Every line, including the last, ends in a newline.
+func Sideways(root *Node) string {
+	var b strings.Builder
+	sideways(&b, root, "", true)
+
+	return b.String()
+}
+
+// sideways writes the subtree rooted at node to b.
+// prefix is drawn before the node's branch, and isTail reports whether node hangs below its parent.
+func sideways(b *strings.Builder, node *Node, prefix string, isTail bool) {
+	if node == nil {
+		return
+	}
+	if node.Right != nil {
+		next := prefix + "    "
+		if isTail {
+			next = prefix + "│   "
+		}
+		sideways(b, node.Right, next, false)
+	}
+	b.WriteString(prefix)
+	if isTail {
+		b.WriteString("└── ")
+	} else {
+		b.WriteString("┌── ")
+	}
+	b.WriteString(node.Label)
+	b.WriteString("\n")
+	if node.Left != nil {
+		next := prefix + "│   "
+		if isTail {
+			next = prefix + "    "
+		}
+		sideways(b, node.Left, next, true)
+	}
+}
+
+// TopDown takes the root of a tree and returns the tree drawn top-down,
+// with every node's label centered over the branches to its children.
+// Every line, including the last, ends in a newline.
+func TopDown(root *Node) string {
+	if root == nil {
+		return ""
+	}
+	var b strings.Builder
+	lines, _, _ := topDown(root)
+	for _, line := range lines {
+		b.WriteString(strings.TrimRight(line, " "))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// topDown returns the lines drawing the subtree rooted at node, all padded to the same width,
+// along with that width and the column the node's label is centered on.
+func topDown(node *Node) (lines []string, width, center int) {
+	labelWidth := utf8.RuneCountInString(node.Label)
+	if node.Left == nil && node.Right == nil {
+		return []string{node.Label}, labelWidth, labelWidth / 2
+	}
+
+	var left, right []string
+	var leftWidth, rightWidth, leftCenter, rightCenter, gap int
+	if node.Left != nil {
+		left, leftWidth, leftCenter = topDown(node.Left)
+	}
+	if node.Right != nil {
+		right, rightWidth, rightCenter = topDown(node.Right)
+		rightCenter += leftWidth
+	}
+	if node.Left != nil && node.Right != nil {
+		gap = 2
+		rightCenter += gap
+	}
+	// the node sits between its children, or just beside an only child
+	switch {
+	case node.Right == nil:
+		center = leftCenter + 2
+	case node.Left == nil:
+		center = rightCenter - 2
+	default:
+		center = (leftCenter + rightCenter) / 2
+	}
+	// shift everything right if the label or branch would start left of column 0
+	offset := 0
+	if start := center - labelWidth/2; start < 0 {
+		offset = -start
+	}
+	if center < 0 && -center > offset {
+		offset = -center
+	}
+	center += offset
+	leftCenter += offset
+	rightCenter += offset
+	width = offset + leftWidth + gap + rightWidth
+	if end := center - labelWidth/2 + labelWidth; end > width {
+		width = end
+	}
+	if center+1 > width {
+		width = center + 1
+	}
+
+	lines = append(lines, pad(strings.Repeat(" ", center-labelWidth/2)+node.Label, width))
+	lines = append(lines, pad(branches(node, center, leftCenter, rightCenter), width))
+	for i := 0; i < len(left) || i < len(right); i++ {
+		line := strings.Repeat(" ", offset)
+		line += row(left, i, leftWidth) + strings.Repeat(" ", gap) + row(right, i, rightWidth)
+		lines = append(lines, pad(line, width))
+	}
+
+	return lines, width, center
+}
+
+// branches returns the line that connects a node centered on center to its children,
+// whose labels are centered on leftCenter and rightCenter.
+func branches(node *Node, center, leftCenter, rightCenter int) string {
+	from, to := center, center
+	if node.Left != nil {
+		from = leftCenter
+	}
+	if node.Right != nil {
+		to = rightCenter
+	}
+	runes := []rune(strings.Repeat(" ", from) + strings.Repeat("─", to-from+1))
+	if node.Left != nil {
+		runes[leftCenter] = '┌'
+	}
+	if node.Right != nil {
+		runes[rightCenter] = '┐'
+	}
+	switch {
+	case node.Left != nil && node.Right != nil:
+		runes[center] = '┴'
+	case node.Left != nil:
+		runes[center] = '┘'
+	default:
+		runes[center] = '└'
+	}
+
+	return string(runes)
+}
+
+// row returns line i of a child's drawing, or blanks of the child's width if the drawing is shorter.
+func row(lines []string, i, width int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+
+	return strings.Repeat(" ", width)
+}
+
+// pad returns line padded with spaces to width runes.
+func pad(line string, width int) string {
+	if n := utf8.RuneCountInString(line); n < width {
+		return line + strings.Repeat(" ", width-n)
+	}
+
+	return line
+}
@@ -0,0 +1,73 @@
+package render
+
+import (
+	"testing"
+)
+
+// leaf returns a Node without children.
+func leaf(label string) *Node {
+	return &Node{Label: label}
+}
+
+func TestSideways(t *testing.T) {
+	root := &Node{Label: "4",
+		Left:  &Node{Label: "2", Left: leaf("1"), Right: leaf("3")},
+		Right: &Node{Label: "6", Left: leaf("5")},
+	}
+	want := "" +
+		"│   ┌── 6\n" +
+		"│   │   └── 5\n" +
+		"└── 4\n" +
+		"    │   ┌── 3\n" +
+		"    └── 2\n" +
+		"        └── 1\n"
+	if got := Sideways(root); got != want {
+		t.Errorf("Sideways() =\n%s\nwant\n%s", got, want)
+	}
+	if got := Sideways(nil); got != "" {
+		t.Errorf("Sideways(nil) = %q, want \"\"", got)
+	}
+}
+
+func TestTopDown(t *testing.T) {
+	tests := []struct {
+		name string
+		root *Node
+		want string
+	}{
+		{"leaf", leaf("10"), "10\n"},
+		{"complete", &Node{Label: "4",
+			Left:  &Node{Label: "2", Left: leaf("1"), Right: leaf("3")},
+			Right: &Node{Label: "6", Left: leaf("5"), Right: leaf("7")},
+		}, "" +
+			"    4\n" +
+			" ┌──┴──┐\n" +
+			" 2     6\n" +
+			"┌┴─┐  ┌┴─┐\n" +
+			"1  3  5  7\n"},
+		{"right chain", &Node{Label: "1", Right: &Node{Label: "2", Right: leaf("3")}}, "" +
+			"1\n" +
+			"└─┐\n" +
+			"  2\n" +
+			"  └─┐\n" +
+			"    3\n"},
+		{"left chain", &Node{Label: "3", Left: &Node{Label: "2", Left: leaf("1")}}, "" +
+			"    3\n" +
+			"  ┌─┘\n" +
+			"  2\n" +
+			"┌─┘\n" +
+			"1\n"},
+		{"wide label", &Node{Label: "root label", Left: leaf("a"), Right: leaf("b")}, "" +
+			"root label\n" +
+			"    ┌┴─┐\n" +
+			"    a  b\n"},
+		{"nil", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TopDown(tt.root); got != tt.want {
+				t.Errorf("TopDown() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
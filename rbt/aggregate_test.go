@@ -0,0 +1,98 @@
+package rbt
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"testing"
+)
+
+// sumOfValues sums int values.
+var sumOfValues = trees.AggregateFuncs{
+	Zero:        0,
+	LiftFunc:    func(key, value interface{}) interface{} { return value },
+	CombineFunc: func(left, right interface{}) interface{} { return left.(int) + right.(int) },
+}
+
+// allKeys is an unbounded Bound.
+var allKeys = trees.Bound{}
+
+// keyList concatenates keys in order, which is associative but not commutative.
+var keyList = trees.AggregateFuncs{
+	Zero:        "",
+	LiftFunc:    func(key, value interface{}) interface{} { return fmt.Sprintf("%d,", key) },
+	CombineFunc: func(left, right interface{}) interface{} { return left.(string) + right.(string) },
+}
+
+func TestAggregateRange_Sum(t *testing.T) {
+	tree := NewWithAggregate(utils.IntComparator, sumOfValues)
+	values := make(map[int]int)
+	r := rand.New(rand.NewSource(1))
+	for step := 0; step < 3000; step++ {
+		key := r.Intn(200)
+		switch r.Intn(3) {
+		case 0:
+			if _, err := tree.Insert(key, step); err == nil {
+				values[key] = step
+			}
+		case 1:
+			tree.Delete(key)
+			delete(values, key)
+		case 2:
+			if _, err := tree.Update(key, -step); err == nil {
+				values[key] = -step
+			}
+		}
+		lo, hi := r.Intn(220)-10, r.Intn(220)-10
+		want := 0
+		for key, value := range values {
+			if key >= lo && key < hi {
+				want += value
+			}
+		}
+		if got := tree.AggregateRange(trees.Including(lo), trees.Excluding(hi)); got != want {
+			t.Fatalf("step %d: AggregateRange([%d, %d)) = %v, want %d", step, lo, hi, got, want)
+		}
+	}
+	total := 0
+	for _, value := range values {
+		total += value
+	}
+	if got := tree.AggregateRange(trees.Bound{}, trees.Bound{}); got != total {
+		t.Errorf("AggregateRange over the whole tree = %v, want %d", got, total)
+	}
+}
+
+func TestAggregateRange_KeepsOrder(t *testing.T) {
+	tree := NewWithAggregate(utils.IntComparator, keyList)
+	for _, key := range rand.New(rand.NewSource(2)).Perm(50) {
+		tree.Insert(key, nil)
+	}
+	for _, key := range []int{7, 21, 22, 40} {
+		tree.Delete(key)
+	}
+	tests := []struct {
+		lo, hi trees.Bound
+		want   string
+	}{
+		{trees.Including(5), trees.Including(10), "5,6,8,9,10,"},
+		{trees.Excluding(19), trees.Excluding(24), "20,23,"},
+		{trees.Including(47), trees.Bound{}, "47,48,49,"},
+		{trees.Bound{}, trees.Excluding(3), "0,1,2,"},
+		{trees.Including(21), trees.Including(22), ""},
+	}
+	for _, tt := range tests {
+		if got := tree.AggregateRange(tt.lo, tt.hi); got != tt.want {
+			t.Errorf("AggregateRange(%v, %v) = %q, want %q", tt.lo, tt.hi, got, tt.want)
+		}
+	}
+}
+
+func TestAggregateRange_WithoutAggregate(t *testing.T) {
+	tree := NewWithIntComparator()
+	tree.Insert(1, 1)
+	if got := tree.AggregateRange(trees.Bound{}, trees.Bound{}); got != nil {
+		t.Errorf("AggregateRange() without an Aggregate = %v, want nil", got)
+	}
+}
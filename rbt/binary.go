@@ -0,0 +1,234 @@
+package rbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/chancetudor/trees"
+	"hash/crc32"
+	"math/bits"
+)
+
+// The binary format written by MarshalBinary is
+//
+//	magic   4 bytes: "RBT" followed by a zero byte
+//	version byte: binaryVersion
+//	count   uvarint: the number of nodes
+//	nodes   every node in pre-order, each as
+//	        flags  byte: 1 if the node has a left child, plus 2 if it has a right child, plus 4 if it is red
+//	        key    the key, encoded by the key Codec
+//	        value  the value, encoded by the value Codec
+//	crc     4 bytes: the little-endian CRC-32 (IEEE) of everything before it
+//
+// Pre-order with child flags is enough to rebuild the exact shape without comparing keys.
+// The checksum catches accidental corruption but not a crafted payload, so UnmarshalBinary also bounds
+// its recursion by the greatest height of a red-black tree with the claimed number of nodes.
+const binaryVersion = 1
+
+// binaryMagic starts every encoded RBT.
+var binaryMagic = []byte("RBT\x00")
+
+// SetBinaryCodecs takes the Codecs that MarshalBinary and UnmarshalBinary use for keys and for values,
+// such as trees.IntCodec{} and trees.StringCodec{}.
+func (tree *RBT) SetBinaryCodecs(key, value trees.Codec) {
+	tree.keyCodec = key
+	tree.valueCodec = value
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler.
+// It encodes the exact shape of the tree in a versioned, checksummed format, keys and values included,
+// using the Codecs set by SetBinaryCodecs. The encoding takes O(n) time.
+// The function returns an error if no Codecs are set or a Codec cannot encode a key or value.
+func (tree *RBT) MarshalBinary() ([]byte, error) {
+	if tree.keyCodec == nil || tree.valueCodec == nil {
+		return nil, fmt.Errorf("ENCODING ERROR: no codecs; call SetBinaryCodecs first")
+	}
+	buf := append([]byte(nil), binaryMagic...)
+	buf = append(buf, binaryVersion)
+	buf = binary.AppendUvarint(buf, uint64(tree.Size()))
+	var err error
+	tree.walk(trees.PreOrder, func(node *Node) bool {
+		var flags byte
+		if node.leftChild() != nil {
+			flags |= 1
+		}
+		if node.rightChild() != nil {
+			flags |= 2
+		}
+		if node.getColor() == RED {
+			flags |= 4
+		}
+		buf = append(buf, flags)
+		if buf, err = tree.keyCodec.Append(buf, node.key()); err != nil {
+			return false
+		}
+		buf, err = tree.valueCodec.Append(buf, node.value())
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf)), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler.
+// It replaces the contents of the tree with the tree encoded by MarshalBinary, rebuilding its exact shape
+// in O(n) without rotations. The tree must already have a comparator and the Codecs the data was encoded with.
+// The function checks the checksum, that the keys are in ascending order, and that the root is black, no red node has a red child, and every path has the same number of black nodes;
+// it returns an error, leaving the tree unchanged, if any check fails.
+func (tree *RBT) UnmarshalBinary(data []byte) error {
+	if tree.keyCodec == nil || tree.valueCodec == nil || tree.comparator == nil {
+		return fmt.Errorf("ENCODING ERROR: the tree needs a comparator and codecs before decoding into it")
+	}
+	header := len(binaryMagic) + 1
+	if len(data) < header+crc32.Size || string(data[:len(binaryMagic)]) != string(binaryMagic) {
+		return fmt.Errorf("ENCODING ERROR: not an encoded RBT")
+	}
+	if data[len(binaryMagic)] != binaryVersion {
+		return fmt.Errorf("ENCODING ERROR: unsupported version %d", data[len(binaryMagic)])
+	}
+	body := data[:len(data)-crc32.Size]
+	if binary.LittleEndian.Uint32(data[len(body):]) != crc32.ChecksumIEEE(body) {
+		return fmt.Errorf("ENCODING ERROR: checksum mismatch")
+	}
+	d := &decoder{tree: tree, data: body, pos: header}
+	count, err := d.uvarint()
+	if err != nil {
+		return err
+	}
+	// every node takes at least one byte, for its flags
+	if count > uint64(len(body)-d.pos) {
+		return fmt.Errorf("ENCODING ERROR: %d nodes do not fit in %d bytes", count, len(body)-d.pos)
+	}
+	d.count = int(count)
+	var root *Node
+	if count > 0 {
+		// a red-black tree with n nodes is at most 2·log2(n+1) high
+		if root, _, err = d.node(2*bits.Len(uint(count)) + 2); err != nil {
+			return err
+		}
+	}
+	if uint64(d.nodes) != count || d.pos != len(body) {
+		return fmt.Errorf("ENCODING ERROR: %d nodes and %d trailing bytes, want %d nodes", d.nodes, len(body)-d.pos, count)
+	}
+	if err := d.checkOrder(root); err != nil {
+		return err
+	}
+	if root != nil && root.getColor() != BLACK {
+		return fmt.Errorf("ENCODING ERROR: the root is red")
+	}
+	tree.setRoot(root)
+	tree.setSize(d.nodes)
+	tree.epoch++
+
+	return nil
+}
+
+// decoder reads the nodes of an encoded RBT.
+type decoder struct {
+	tree  *RBT   // the tree being decoded into, for its comparator, codecs, and aggregate
+	data  []byte // the encoding without its checksum
+	pos   int    // the offset of the next unread byte
+	nodes int    // the number of nodes decoded so far
+	count int    // the number of nodes the encoding claims to hold
+}
+
+// uvarint reads an unsigned varint.
+func (d *decoder) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, fmt.Errorf("ENCODING ERROR: invalid varint at byte %d", d.pos)
+	}
+	d.pos += n
+
+	return v, nil
+}
+
+// decode reads a key or value with codec.
+func (d *decoder) decode(codec trees.Codec) (interface{}, error) {
+	v, n, err := codec.Decode(d.data[d.pos:])
+	if err != nil {
+		return nil, fmt.Errorf("ENCODING ERROR: at byte %d: %w", d.pos, err)
+	}
+	d.pos += n
+
+	return v, nil
+}
+
+// node reads a node and, recursively, its subtrees,
+// and returns the node and the number of black nodes on every path from it down to a nil leaf.
+// The subtree may have at most limit levels, so the recursion depth is bounded whatever the data says.
+func (d *decoder) node(limit int) (*Node, int, error) {
+	if d.pos >= len(d.data) {
+		return nil, 0, fmt.Errorf("ENCODING ERROR: unexpected end of data")
+	}
+	if d.nodes >= d.count {
+		return nil, 0, fmt.Errorf("ENCODING ERROR: more than %d nodes", d.count)
+	}
+	if limit < 1 {
+		return nil, 0, fmt.Errorf("ENCODING ERROR: the tree is too deep at byte %d", d.pos)
+	}
+	flags := d.data[d.pos]
+	if flags&^7 != 0 {
+		return nil, 0, fmt.Errorf("ENCODING ERROR: invalid flags at byte %d", d.pos)
+	}
+	d.pos++
+	key, err := d.decode(d.tree.keyCodec)
+	if err != nil {
+		return nil, 0, err
+	}
+	value, err := d.decode(d.tree.valueCodec)
+	if err != nil {
+		return nil, 0, err
+	}
+	d.nodes++
+	color := BLACK
+	if flags&4 != 0 {
+		color = RED
+	}
+	node := NewNode(key, value, color)
+	var blackHeights [2]int
+	for i, side := range []byte{1, 2} {
+		if flags&side == 0 {
+			continue
+		}
+		child, blackHeight, err := d.node(limit - 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		if color == RED && child.getColor() == RED {
+			return nil, 0, fmt.Errorf("ENCODING ERROR: red node %v has a red child", key)
+		}
+		if side == 1 {
+			node.setLeftChild(child)
+		} else {
+			node.setRightChild(child)
+		}
+		child.setParent(node)
+		blackHeights[i] = blackHeight
+	}
+	if blackHeights[0] != blackHeights[1] {
+		return nil, 0, fmt.Errorf("ENCODING ERROR: the subtrees of node %v have different black heights", key)
+	}
+	d.tree.refresh(node)
+	if color == BLACK {
+		blackHeights[0]++
+	}
+
+	return node, blackHeights[0], nil
+}
+
+// checkOrder returns an error unless the keys of the subtree rooted at root are in strictly ascending order.
+func (d *decoder) checkOrder(root *Node) error {
+	if root == nil {
+		return nil
+	}
+	prev := root.subtreeMin()
+	for node := prev.successor(); node != nil; prev, node = node, node.successor() {
+		if d.tree.comparator(prev.key(), node.key()) >= 0 {
+			return fmt.Errorf("ENCODING ERROR: key %v is not greater than the key before it", node.key())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+package rbt
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/emirpasic/gods/utils"
+	"hash/crc32"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newCodecTree returns a tree holding n random int keys, each mapped to its decimal string,
+// with the int and string codecs set.
+func newCodecTree(n int) *RBT {
+	tree := NewWithIntComparator()
+	tree.SetBinaryCodecs(trees.IntCodec{}, trees.StringCodec{})
+	for _, key := range rand.New(rand.NewSource(1)).Perm(n) {
+		tree.Insert(key-n/2, strconv.Itoa(key-n/2))
+	}
+
+	return tree
+}
+
+// shape returns the keys of the tree in pre-order, each with the node's balance information.
+func shape(tree *RBT) []string {
+	var nodes []string
+	tree.walk(trees.PreOrder, func(node *Node) bool {
+		nodes = append(nodes, fmt.Sprintf("%v/%d", node.key(), node.getColor()))
+		return true
+	})
+
+	return nodes
+}
+
+func TestRBT_MarshalBinary(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 100, 5000} {
+		tree := newCodecTree(n)
+		data, err := tree.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() error = %v", err)
+		}
+		decoded := newCodecTree(0)
+		decoded.Insert(1<<40, "replaced")
+		if err := decoded.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() of %d nodes error = %v", n, err)
+		}
+		if !reflect.DeepEqual(shape(decoded), shape(tree)) {
+			t.Fatalf("UnmarshalBinary() of %d nodes rebuilt a different shape", n)
+		}
+		if decoded.Size() != n || decoded.Search(1<<40) {
+			t.Errorf("UnmarshalBinary() left %d nodes, want %d", decoded.Size(), n)
+		}
+		checkRBT(t, decoded)
+		checkSizes(t, decoded.Root())
+		it := decoded.Iterator()
+		for it.Next() {
+			if it.Value() != strconv.Itoa(it.Key().(int)) {
+				t.Fatalf("UnmarshalBinary() mapped %v to %v", it.Key(), it.Value())
+			}
+		}
+		// the decoded tree stays usable
+		if _, err := decoded.Insert(n, "new"); err != nil {
+			t.Errorf("Insert() after UnmarshalBinary() error = %v", err)
+		}
+	}
+}
+
+func TestRBT_MarshalBinaryAggregate(t *testing.T) {
+	tree := NewWithAggregate(utils.IntComparator, sumOfValues)
+	tree.SetBinaryCodecs(trees.IntCodec{}, trees.IntCodec{})
+	for i := 0; i < 100; i++ {
+		tree.Insert(i, i)
+	}
+	data, err := tree.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	decoded := NewWithAggregate(utils.IntComparator, sumOfValues)
+	decoded.SetBinaryCodecs(trees.IntCodec{}, trees.IntCodec{})
+	if err := decoded.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if got := decoded.AggregateRange(trees.Including(10), trees.Excluding(20)); got != 145 {
+		t.Errorf("AggregateRange() after UnmarshalBinary() = %v, want 145", got)
+	}
+}
+
+func TestRBT_UnmarshalBinaryErrors(t *testing.T) {
+	data, err := newCodecTree(50).MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	// corrupt returns a copy of data with the byte at i changed
+	corrupt := func(i int) []byte {
+		bad := append([]byte(nil), data...)
+		bad[i] ^= 0x40
+		return bad
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"truncated", data[:len(data)-1]},
+		{"magic", corrupt(0)},
+		{"version", corrupt(len(binaryMagic))},
+		{"payload", corrupt(len(data) / 2)},
+		{"checksum", corrupt(len(data) - 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newCodecTree(3)
+			if err := tree.UnmarshalBinary(tt.data); err == nil {
+				t.Fatalf("UnmarshalBinary() returned no error")
+			}
+			if tree.Size() != 3 {
+				t.Errorf("UnmarshalBinary() changed the tree after failing")
+			}
+		})
+	}
+	t.Run("order", func(t *testing.T) {
+		reversed := NewWith(func(a, b interface{}) int {
+			return utils.IntComparator(b, a)
+		})
+		reversed.SetBinaryCodecs(trees.IntCodec{}, trees.StringCodec{})
+		if err := reversed.UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary() accepted keys out of order")
+		}
+	})
+	t.Run("no codecs", func(t *testing.T) {
+		if _, err := NewWithIntComparator().MarshalBinary(); err == nil {
+			t.Errorf("MarshalBinary() without codecs returned no error")
+		}
+		if err := NewWithIntComparator().UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary() without codecs returned no error")
+		}
+	})
+	t.Run("wrong codec", func(t *testing.T) {
+		tree := newCodecTree(3)
+		tree.SetBinaryCodecs(trees.StringCodec{}, trees.StringCodec{})
+		if _, err := tree.MarshalBinary(); err == nil {
+			t.Errorf("MarshalBinary() encoded int keys with StringCodec")
+		}
+	})
+	t.Run("red root", func(t *testing.T) {
+		tree := newCodecTree(10)
+		tree.Root().setColor(RED)
+		data, err := tree.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() error = %v", err)
+		}
+		if err := newCodecTree(0).UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary() accepted a red root")
+		}
+	})
+	t.Run("black height", func(t *testing.T) {
+		tree := newCodecTree(10)
+		tree.maxNode().setColor(1 - tree.maxNode().getColor())
+		data, err := tree.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() error = %v", err)
+		}
+		if err := newCodecTree(0).UnmarshalBinary(data); err == nil {
+			t.Errorf("UnmarshalBinary() accepted unequal black heights")
+		}
+	})
+	// chain returns a payload with a valid checksum that claims count nodes and holds n of them,
+	// each the left child of the one before it
+	chain := func(count uint64, n int) []byte {
+		buf := append(append([]byte(nil), binaryMagic...), binaryVersion)
+		buf = binary.AppendUvarint(buf, count)
+		for i := 0; i < n; i++ {
+			flags := byte(1)
+			if i == n-1 {
+				flags = 0
+			}
+			buf = append(buf, flags)
+			buf, _ = trees.IntCodec{}.Append(buf, n-i)
+			buf, _ = trees.StringCodec{}.Append(buf, "")
+		}
+		return binary.LittleEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf))
+	}
+	t.Run("deep", func(t *testing.T) {
+		err := newCodecTree(0).UnmarshalBinary(chain(100000, 100000))
+		if err == nil || !strings.Contains(err.Error(), "too deep") {
+			t.Errorf("UnmarshalBinary() of a 100000-node chain = %v, want an error before recursing", err)
+		}
+	})
+	t.Run("count", func(t *testing.T) {
+		err := newCodecTree(0).UnmarshalBinary(chain(1<<40, 1))
+		if err == nil || !strings.Contains(err.Error(), "do not fit") {
+			t.Errorf("UnmarshalBinary() of a huge count = %v, want an error", err)
+		}
+	})
+}
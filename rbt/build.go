@@ -0,0 +1,77 @@
+package rbt
+
+import (
+	"fmt"
+	"github.com/emirpasic/gods/utils"
+	"math/bits"
+)
+
+// FromSorted takes a comparator and keys in strictly ascending order, with their values,
+// and returns a pointer to a perfectly balanced RBT holding them.
+// It runs in O(n); the only comparisons are the ones that check that the keys are sorted.
+// Only the deepest level is colored red, so the tree satisfies IsBalanced.
+// values may be nil, in which case every value is nil; otherwise it must be as long as keys.
+// The function returns a DuplicateError or an UnsortedError, and no tree, if the keys are not strictly ascending.
+func FromSorted(comparator utils.Comparator, keys, values []interface{}) (*RBT, error) {
+	tree := NewWith(comparator)
+	if err := tree.loadSorted(keys, values); err != nil {
+		return nil, err
+	}
+
+	return tree, nil
+}
+
+// loadSorted replaces the contents of the tree with a perfectly balanced tree built from sorted keys and values.
+// The tree is left unchanged if the input is invalid.
+func (tree *RBT) loadSorted(keys, values []interface{}) error {
+	if values != nil && len(values) != len(keys) {
+		return fmt.Errorf("LENGTH ERROR: %d keys but %d values", len(keys), len(values))
+	}
+	for i := 1; i < len(keys); i++ {
+		compare := tree.comparator(keys[i-1], keys[i])
+		switch {
+		case compare == 0:
+			return NewDuplicateError(keys[i])
+		case compare > 0:
+			return NewUnsortedError(keys[i])
+		}
+	}
+	tree.setRoot(tree.buildSorted(keys, values, 0, len(keys), 0, bits.Len(uint(len(keys)))-1))
+	tree.setSize(len(keys))
+	tree.epoch++
+
+	return nil
+}
+
+// buildSorted builds a subtree from keys[lo:hi] by making the middle key the root and recursing on each half,
+// and returns the subtree's root.
+// depth is the depth of that root, and maxDepth the depth of the deepest level of the whole tree.
+func (tree *RBT) buildSorted(keys, values []interface{}, lo, hi, depth, maxDepth int) *Node {
+	if lo >= hi {
+		return nil
+	}
+	mid := lo + (hi-lo)/2
+	// every path to a nil leaf passes through one node at each depth up to maxDepth-1,
+	// so coloring only the deepest level red gives every path the same black height
+	color := BLACK
+	if depth == maxDepth && depth > 0 {
+		color = RED
+	}
+	node := NewNode(keys[mid], valueAt(values, mid), color)
+	node.setLeftChild(tree.buildSorted(keys, values, lo, mid, depth+1, maxDepth))
+	node.leftChild().setParent(node)
+	node.setRightChild(tree.buildSorted(keys, values, mid+1, hi, depth+1, maxDepth))
+	node.rightChild().setParent(node)
+	tree.refresh(node)
+
+	return node
+}
+
+// valueAt returns values[i], or nil if values is nil.
+func valueAt(values []interface{}, i int) interface{} {
+	if values == nil {
+		return nil
+	}
+
+	return values[i]
+}
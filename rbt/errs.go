@@ -2,6 +2,7 @@ package rbt
 
 import (
 	"fmt"
+	"strings"
 )
 
 type DuplicateError struct {
@@ -127,3 +128,100 @@ func (e *NilNodeError) Error() string {
 		return fmt.Sprintf(e.Message+"Key = %+v"+" does not exist in the tree.", e.Key)
 	}
 }
+
+type OverlapError struct {
+	Key     interface{}
+	Message string
+}
+
+func NewOverlapError(k interface{}) *OverlapError {
+	return &OverlapError{
+		Key:     k,
+		Message: "OVERLAP ERROR: ",
+	}
+}
+
+func (e *OverlapError) Error() string {
+	return fmt.Sprintf(e.Message+"Key = %+v"+" is not greater than every key of the left tree. "+
+		"Join requires every key of the left tree to be smaller than every key of the right tree.", e.Key)
+}
+
+type StaleHandleError struct {
+	Key     interface{}
+	Message string
+}
+
+func NewStaleHandleError(k interface{}) *StaleHandleError {
+	return &StaleHandleError{
+		Key:     k,
+		Message: "STALE HANDLE ERROR: ",
+	}
+}
+
+func (e *StaleHandleError) Error() string {
+	return fmt.Sprintf(e.Message+"Key = %+v"+" is no longer in the tree the handle came from. "+
+		"Please use Find() to get a handle to the current entry.", e.Key)
+}
+
+type UnsortedError struct {
+	Key     interface{}
+	Message string
+}
+
+func NewUnsortedError(k interface{}) *UnsortedError {
+	return &UnsortedError{
+		Key:     k,
+		Message: "UNSORTED ERROR: ",
+	}
+}
+
+func (e *UnsortedError) Error() string {
+	return fmt.Sprintf(e.Message+"Key = %+v"+" is smaller than the key before it. "+
+		"Keys must be in strictly ascending order.", e.Key)
+}
+
+// Rule names an invariant that Validate checks.
+type Rule string
+
+const (
+	RuleOrder       Rule = "ORDER"        // an in-order walk meets the keys in strictly ascending order
+	RuleParent      Rule = "PARENT"       // every child points back at its parent, and the root has no parent
+	RuleStructure   Rule = "STRUCTURE"    // no node is reachable twice, so the nodes form a tree
+	RuleSize        Rule = "SIZE"         // the tree's size is its number of nodes
+	RuleColor       Rule = "COLOR"        // every node is RED or BLACK
+	RuleRootColor   Rule = "ROOT COLOR"   // the root is black
+	RuleRedRed      Rule = "RED RED"      // no red node has a red child
+	RuleBlackHeight Rule = "BLACK HEIGHT" // every path from a node to a nil leaf has the same number of black nodes
+	RuleSubtreeSize Rule = "SUBTREE SIZE" // every stored subtree size is the subtree's number of nodes
+	RuleAggregate   Rule = "AGGREGATE"    // every stored aggregate combines the subtree's entries
+)
+
+// Violation records one node that breaks one Rule.
+// Key is the offending node's key, or nil for a Rule about the whole tree.
+type Violation struct {
+	Key    interface{}
+	Rule   Rule
+	Detail string
+}
+
+type ValidationError struct {
+	Violations []Violation
+	Message    string
+}
+
+func NewValidationError(violations []Violation) *ValidationError {
+	return &ValidationError{
+		Violations: violations,
+		Message:    "VALIDATION ERROR: ",
+	}
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Message + fmt.Sprintf("%d violations", len(e.Violations)))
+	for _, v := range e.Violations {
+		b.WriteString(fmt.Sprintf("; Key = %+v breaks %s: %s", v.Key, v.Rule, v.Detail))
+	}
+
+	return b.String()
+}
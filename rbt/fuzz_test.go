@@ -0,0 +1,25 @@
+package rbt
+
+import (
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/treetest"
+	"testing"
+)
+
+// newModelTree returns an empty RBT as a trees.OrderedMap for the model-based tests.
+func newModelTree() trees.OrderedMap {
+	return NewWithIntComparator()
+}
+
+// validate checks every invariant of a RBT after each operation of a model-based test.
+func validate(m trees.OrderedMap) error {
+	return m.(*RBT).Validate()
+}
+
+func TestRBT_Model(t *testing.T) {
+	treetest.TestModel(t, newModelTree, validate)
+}
+
+func FuzzRBT(f *testing.F) {
+	treetest.Fuzz(f, newModelTree, validate)
+}
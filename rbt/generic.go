@@ -0,0 +1,372 @@
+package rbt
+
+import (
+	"cmp"
+)
+
+// Tree is a type-parameterized red-black tree mapping keys of type K to values of type V.
+// It offers the same operations as RBT without boxing keys and values in interface{},
+// and keys are ordered by a func(a, b K) int instead of a gods comparator.
+// Duplicates are not allowed.
+type Tree[K, V any] struct {
+	root    *genericNode[K, V] // the root node
+	compare func(a, b K) int   // the key comparator
+	size    int                // number of nodes in the tree
+}
+
+// genericNode stores left, right, and parent node pointers, the node's color,
+// and the key and value of an entry in a Tree.
+type genericNode[K, V any] struct {
+	left   *genericNode[K, V]
+	right  *genericNode[K, V]
+	parent *genericNode[K, V]
+	color  int
+	key    K
+	value  V
+}
+
+// getColor returns a node's color, either red (1) or black (0).
+// Returns black if the node is nil.
+func (node *genericNode[K, V]) getColor() int {
+	if node == nil {
+		return BLACK
+	}
+
+	return node.color
+}
+
+// New returns a pointer to an empty Tree whose keys are ordered by cmp.Compare.
+func New[K cmp.Ordered, V any]() *Tree[K, V] {
+	return NewFunc[K, V](cmp.Compare[K])
+}
+
+// NewFunc returns a pointer to an empty Tree whose keys are ordered by compare.
+// compare must return a negative number when a < b, a positive number when a > b, and 0 when a == b.
+func NewFunc[K, V any](compare func(a, b K) int) *Tree[K, V] {
+	return &Tree[K, V]{
+		root:    nil,
+		compare: compare,
+		size:    0,
+	}
+}
+
+// Insert takes a key and a value and inserts a new red node with that key and value,
+// then restores the red-black invariants.
+// The function returns the newly inserted node's key, or the zero key and an error, if there was one.
+func (tree *Tree[K, V]) Insert(key K, value V) (K, error) {
+	var parent *genericNode[K, V]
+	compare := 0
+	for tempNode := tree.root; tempNode != nil; {
+		parent = tempNode
+		compare = tree.compare(key, tempNode.key)
+		switch {
+		case compare < 0:
+			tempNode = tempNode.left
+		case compare > 0:
+			tempNode = tempNode.right
+		default:
+			var zero K
+			return zero, NewDuplicateError(key)
+		}
+	}
+
+	newNode := &genericNode[K, V]{key: key, value: value, parent: parent, color: RED}
+	switch {
+	case parent == nil:
+		tree.root = newNode
+	case compare < 0:
+		parent.left = newNode
+	default:
+		parent.right = newNode
+	}
+	tree.insertFixup(newNode)
+	tree.size++
+
+	return key, nil
+}
+
+// insertFixup performs rotations and recolorations after insertion.
+// The cases are the same as in RBT.insertFixup.
+func (tree *Tree[K, V]) insertFixup(node *genericNode[K, V]) {
+	for node.parent.getColor() == RED {
+		parent := node.parent
+		grandparent := parent.parent
+		if parent == grandparent.left {
+			uncle := grandparent.right
+			switch {
+			case uncle.getColor() == RED: // case 1
+				parent.color = BLACK
+				uncle.color = BLACK
+				grandparent.color = RED
+				node = grandparent
+			case node == parent.right: // case 2
+				node = parent
+				tree.leftRotate(node)
+			default: // case 3
+				parent.color = BLACK
+				grandparent.color = RED
+				tree.rightRotate(grandparent)
+			}
+		} else {
+			uncle := grandparent.left
+			switch {
+			case uncle.getColor() == RED: // case 1
+				parent.color = BLACK
+				uncle.color = BLACK
+				grandparent.color = RED
+				node = grandparent
+			case node == parent.left: // case 2
+				node = parent
+				tree.rightRotate(node)
+			default: // case 3
+				parent.color = BLACK
+				grandparent.color = RED
+				tree.leftRotate(grandparent)
+			}
+		}
+	}
+	tree.root.color = BLACK
+}
+
+// Search takes a key and searches for the key in the tree.
+// The function returns a boolean, stating whether the key was found or not.
+func (tree *Tree[K, V]) Search(key K) bool {
+	return tree.findNode(key) != nil
+}
+
+// Update takes a key and a value and updates a node with the existing key with the new value.
+// Returns the new value of the node or an error, if there was one.
+func (tree *Tree[K, V]) Update(key K, value V) (V, error) {
+	matchingNode := tree.findNode(key)
+	if matchingNode == nil {
+		var zero V
+		return zero, NewNilNodeError(key)
+	}
+	matchingNode.value = value
+
+	return matchingNode.value, nil
+}
+
+// ReturnNodeValue takes a key and returns the value associated with the key or an error, if there was one.
+func (tree *Tree[K, V]) ReturnNodeValue(key K) (V, error) {
+	matchingNode := tree.findNode(key)
+	if matchingNode == nil {
+		var zero V
+		return zero, NewNilNodeError(key)
+	}
+
+	return matchingNode.value, nil
+}
+
+// Delete takes a key, removes the node from the tree, and decrements the size of the tree.
+// The node is unlinked rather than overwritten with its successor's entry.
+// The function returns the key of the deleted node, or the zero key and an error, if there was one.
+func (tree *Tree[K, V]) Delete(key K) (K, error) {
+	nodeToDelete := tree.findNode(key)
+	if nodeToDelete == nil {
+		var zero K
+		return zero, NewNilNodeError(key)
+	}
+
+	// x is the node that moves into the removed node's place; it may be nil, so track its parent too.
+	var x, xParent *genericNode[K, V]
+	removedColor := nodeToDelete.color
+	switch {
+	case nodeToDelete.left == nil:
+		x, xParent = nodeToDelete.right, nodeToDelete.parent
+		tree.replaceSubTree(nodeToDelete, x)
+	case nodeToDelete.right == nil:
+		x, xParent = nodeToDelete.left, nodeToDelete.parent
+		tree.replaceSubTree(nodeToDelete, x)
+	default: // the node to delete has two subtrees
+		successor := nodeToDelete.right
+		for successor.left != nil {
+			successor = successor.left
+		}
+		removedColor = successor.color
+		x = successor.right
+		if successor.parent == nodeToDelete {
+			xParent = successor
+		} else {
+			xParent = successor.parent
+			tree.replaceSubTree(successor, x)
+			successor.right = nodeToDelete.right
+			successor.right.parent = successor
+		}
+		tree.replaceSubTree(nodeToDelete, successor)
+		successor.left = nodeToDelete.left
+		successor.left.parent = successor
+		successor.color = nodeToDelete.color
+	}
+	nodeToDelete.left, nodeToDelete.right, nodeToDelete.parent = nil, nil, nil
+	if removedColor == BLACK {
+		tree.deleteFixup(x, xParent)
+	}
+	tree.size--
+
+	return nodeToDelete.key, nil
+}
+
+// deleteFixup maintains the invariants of the red-black tree after deletion.
+// x carries an extra black; parent is x's parent, which is needed when x is nil.
+func (tree *Tree[K, V]) deleteFixup(x, parent *genericNode[K, V]) {
+	for x != tree.root && x.getColor() == BLACK {
+		if x == parent.left {
+			w := parent.right
+			if w.getColor() == RED {
+				w.color = BLACK
+				parent.color = RED
+				tree.leftRotate(parent)
+				w = parent.right
+			}
+			if w.left.getColor() == BLACK && w.right.getColor() == BLACK {
+				w.color = RED
+				x, parent = parent, parent.parent
+				continue
+			}
+			if w.right.getColor() == BLACK {
+				w.left.color = BLACK
+				w.color = RED
+				tree.rightRotate(w)
+				w = parent.right
+			}
+			w.color = parent.color
+			parent.color = BLACK
+			w.right.color = BLACK
+			tree.leftRotate(parent)
+		} else {
+			w := parent.left
+			if w.getColor() == RED {
+				w.color = BLACK
+				parent.color = RED
+				tree.rightRotate(parent)
+				w = parent.left
+			}
+			if w.left.getColor() == BLACK && w.right.getColor() == BLACK {
+				w.color = RED
+				x, parent = parent, parent.parent
+				continue
+			}
+			if w.left.getColor() == BLACK {
+				w.right.color = BLACK
+				w.color = RED
+				tree.leftRotate(w)
+				w = parent.left
+			}
+			w.color = parent.color
+			parent.color = BLACK
+			w.left.color = BLACK
+			tree.rightRotate(parent)
+		}
+		x = tree.root
+	}
+	if x != nil {
+		x.color = BLACK
+	}
+}
+
+// Clear sets the root node to nil and sets the size of the tree to 0.
+func (tree *Tree[K, V]) Clear() {
+	tree.root = nil
+	tree.size = 0
+}
+
+// Size returns the size, or number of nodes in the tree, of the tree.
+func (tree *Tree[K, V]) Size() int {
+	return tree.size
+}
+
+// IsEmpty returns a boolean stating whether the tree is empty or not.
+func (tree *Tree[K, V]) IsEmpty() bool {
+	return tree.size == 0
+}
+
+// BlackHeight returns an int representing the black height of the tree,
+// or -1 if two paths from the root disagree.
+func (tree *Tree[K, V]) BlackHeight() int {
+	if tree.root == nil {
+		return 0
+	}
+
+	return tree.root.blackHeight()
+}
+
+// IsBalanced returns a bool representing whether
+// all paths from the root to its nil descendants contain the same number of black nodes.
+func (tree *Tree[K, V]) IsBalanced() bool {
+	return tree.BlackHeight() >= 0
+}
+
+// blackHeight returns an int representing the black height from a given node, or -1 if it is unbalanced.
+func (node *genericNode[K, V]) blackHeight() int {
+	if node == nil {
+		return 1
+	}
+	leftBlackHeight := node.left.blackHeight()
+	if leftBlackHeight < 0 || leftBlackHeight != node.right.blackHeight() {
+		return -1
+	}
+	if node.color == BLACK {
+		leftBlackHeight++
+	}
+
+	return leftBlackHeight
+}
+
+// findNode takes a key and returns the node associated with that key, or nil if no node exists.
+func (tree *Tree[K, V]) findNode(key K) *genericNode[K, V] {
+	tempNode := tree.root
+	for tempNode != nil {
+		compare := tree.compare(key, tempNode.key)
+		switch {
+		case compare < 0:
+			tempNode = tempNode.left
+		case compare > 0:
+			tempNode = tempNode.right
+		default:
+			return tempNode
+		}
+	}
+
+	return nil
+}
+
+// replaceSubTree replaces the subtree rooted at toDelete with the subtree rooted at replacement.
+func (tree *Tree[K, V]) replaceSubTree(toDelete, replacement *genericNode[K, V]) {
+	parent := toDelete.parent
+	switch {
+	case parent == nil:
+		tree.root = replacement
+	case toDelete == parent.left:
+		parent.left = replacement
+	default:
+		parent.right = replacement
+	}
+	if replacement != nil {
+		replacement.parent = parent
+	}
+}
+
+// leftRotate makes node's right child the root of node's subtree.
+func (tree *Tree[K, V]) leftRotate(node *genericNode[K, V]) {
+	newParent := node.right
+	node.right = newParent.left
+	if newParent.left != nil {
+		newParent.left.parent = node
+	}
+	tree.replaceSubTree(node, newParent)
+	newParent.left = node
+	node.parent = newParent
+}
+
+// rightRotate makes node's left child the root of node's subtree.
+func (tree *Tree[K, V]) rightRotate(node *genericNode[K, V]) {
+	newParent := node.left
+	node.left = newParent.right
+	if newParent.right != nil {
+		newParent.right.parent = node
+	}
+	tree.replaceSubTree(node, newParent)
+	newParent.right = node
+	node.parent = newParent
+}
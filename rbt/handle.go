@@ -0,0 +1,93 @@
+package rbt
+
+// Handle refers to one entry of a RBT. Since deleting a key unlinks its node instead of moving
+// another entry into it, a Handle stays valid through any number of insertions, updates, and deletions of other keys,
+// until its own entry is deleted or the tree's contents are replaced by Clear, Split, Join, a set operation,
+// or unmarshalling. Every method takes O(1) time, except Next and Prev, which take O(1) amortized time.
+// The zero Handle is never valid.
+type Handle struct {
+	tree  *RBT  // the tree the entry belongs to
+	node  *Node // the entry's node
+	epoch int   // the tree's epoch when the Handle was made
+}
+
+// Find takes a key and returns a Handle to its entry, or the zero Handle if the key is not in the tree.
+func (tree *RBT) Find(key interface{}) Handle {
+	node, err := tree.findNode(key)
+	if err != nil {
+		return Handle{}
+	}
+
+	return tree.handle(node)
+}
+
+// First returns a Handle to the entry with the smallest key, or the zero Handle if the tree is empty.
+func (tree *RBT) First() Handle {
+	return tree.handle(tree.minNode())
+}
+
+// Last returns a Handle to the entry with the largest key, or the zero Handle if the tree is empty.
+func (tree *RBT) Last() Handle {
+	return tree.handle(tree.maxNode())
+}
+
+// handle returns a Handle to node, or the zero Handle if node is nil.
+func (tree *RBT) handle(node *Node) Handle {
+	if node == nil {
+		return Handle{}
+	}
+
+	return Handle{tree: tree, node: node, epoch: tree.epoch}
+}
+
+// Valid reports whether the Handle's entry is still in the tree the Handle came from.
+func (h Handle) Valid() bool {
+	// a deleted node has size 0; replacing every entry moves the tree to a new epoch
+	return h.node != nil && h.node.subtreeSize() > 0 && h.epoch == h.tree.epoch
+}
+
+// Key returns the key of the Handle's entry, or nil for the zero Handle.
+// A Handle whose entry was deleted still returns the entry's key.
+func (h Handle) Key() interface{} {
+	return h.node.key()
+}
+
+// Value returns the value of the Handle's entry, or nil for the zero Handle.
+// A Handle whose entry was deleted still returns the entry's last value.
+func (h Handle) Value() interface{} {
+	return h.node.value()
+}
+
+// SetValue takes a value and stores it as the value of the Handle's entry, without searching for its key.
+// The function returns a StaleHandleError, leaving the tree unchanged, if the Handle is not valid.
+func (h Handle) SetValue(value interface{}) error {
+	if !h.Valid() {
+		return NewStaleHandleError(h.Key())
+	}
+	h.node.setValue(value)
+	if h.tree.aggregate != nil {
+		h.tree.refreshPath(h.node)
+	}
+
+	return nil
+}
+
+// Next returns a Handle to the entry with the next larger key,
+// or the zero Handle if there is none or the Handle is not valid.
+func (h Handle) Next() Handle {
+	if !h.Valid() {
+		return Handle{}
+	}
+
+	return h.tree.handle(h.node.successor())
+}
+
+// Prev returns a Handle to the entry with the next smaller key,
+// or the zero Handle if there is none or the Handle is not valid.
+func (h Handle) Prev() Handle {
+	if !h.Valid() {
+		return Handle{}
+	}
+
+	return h.tree.handle(h.node.predecessor())
+}
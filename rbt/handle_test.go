@@ -0,0 +1,146 @@
+package rbt
+
+import (
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"testing"
+)
+
+func TestHandle_StableAcrossDeletes(t *testing.T) {
+	tree := newShuffledTree(1000)
+	var handles []Handle
+	for h := tree.First(); h.Valid(); h = h.Next() {
+		handles = append(handles, h)
+	}
+	if len(handles) != 1000 {
+		t.Fatalf("First() and Next() visited %d entries, want 1000", len(handles))
+	}
+
+	deleted := make(map[int]bool)
+	for _, i := range rand.New(rand.NewSource(2)).Perm(1000)[:500] {
+		tree.Delete(2 * i)
+		deleted[2*i] = true
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	for i, h := range handles {
+		key := 2 * i
+		if h.Key() != key || h.Value() != 10*key {
+			t.Fatalf("handle %d holds %v: %v, want %d: %d", i, h.Key(), h.Value(), key, 10*key)
+		}
+		if h.Valid() == deleted[key] {
+			t.Fatalf("handle to %d: Valid() = %v, deleted = %v", key, h.Valid(), deleted[key])
+		}
+		if !deleted[key] && tree.Find(key) != h {
+			t.Fatalf("Find(%d) returned a different handle", key)
+		}
+	}
+
+	// Next and Prev from the surviving handles skip the deleted entries
+	prev := Handle{}
+	for _, h := range handles {
+		if !h.Valid() {
+			continue
+		}
+		if h.Prev() != prev {
+			t.Fatalf("Prev() of %v = %v, want %v", h.Key(), h.Prev().Key(), prev.Key())
+		}
+		if prev.Valid() && prev.Next() != h {
+			t.Fatalf("Next() of %v = %v, want %v", prev.Key(), prev.Next().Key(), h.Key())
+		}
+		prev = h
+	}
+	if prev != tree.Last() || prev.Next().Valid() {
+		t.Errorf("Last() = %v, want %v with no next entry", tree.Last().Key(), prev.Key())
+	}
+}
+
+func TestHandle_DeleteTwoChildren(t *testing.T) {
+	tree := newShuffledTree(100)
+	root := tree.Root()
+	rootKey := root.Data.Key
+	successor := tree.Find(rootKey).Next()
+	successorKey := successor.Key()
+
+	// the root has two children, so its successor takes its place in the tree
+	tree.Delete(rootKey)
+	if root.Data.Key != rootKey {
+		t.Errorf("the deleted root node now holds key %v, want %v", root.Data.Key, rootKey)
+	}
+	if !successor.Valid() || successor.Key() != successorKey || tree.Root() != successor.node {
+		t.Errorf("the successor's handle holds %v, valid = %v, want %v at the root",
+			successor.Key(), successor.Valid(), successorKey)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandle_SetValue(t *testing.T) {
+	tree := NewWithAggregate(utils.IntComparator, sumOfValues)
+	for i := 0; i < 10; i++ {
+		tree.Insert(i, 1)
+	}
+	h := tree.Find(4)
+	if err := h.SetValue(100); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := tree.ReturnNodeValue(4); value != 100 {
+		t.Errorf("ReturnNodeValue(4) = %v after SetValue(100)", value)
+	}
+	if got := tree.AggregateRange(allKeys, allKeys); got != 109 {
+		t.Errorf("AggregateRange() = %v after SetValue, want 109", got)
+	}
+
+	tree.Delete(4)
+	err := h.SetValue(5)
+	if _, ok := err.(*StaleHandleError); !ok {
+		t.Errorf("SetValue() on a deleted entry error = %v, want a StaleHandleError", err)
+	}
+	if got := tree.AggregateRange(allKeys, allKeys); got != 9 {
+		t.Errorf("AggregateRange() = %v after a stale SetValue, want 9", got)
+	}
+}
+
+func TestHandle_Invalidated(t *testing.T) {
+	var zero Handle
+	if zero.Valid() || zero.Key() != nil || zero.Value() != nil || zero.Next().Valid() || zero.Prev().Valid() {
+		t.Errorf("the zero Handle is usable")
+	}
+	if _, ok := zero.SetValue(1).(*StaleHandleError); !ok {
+		t.Errorf("SetValue() on the zero Handle did not return a StaleHandleError")
+	}
+
+	tree := newShuffledTree(10)
+	if tree.Find(1).Valid() {
+		t.Errorf("Find() of a missing key returned a valid handle")
+	}
+	h := tree.Find(4)
+	tree.Clear()
+	tree.Insert(4, 40)
+	if h.Valid() || h.Next().Valid() {
+		t.Errorf("a handle is still valid after Clear()")
+	}
+
+	h = tree.Find(4)
+	less, rest := tree.Split(4)
+	if h.Valid() || less.Size()+rest.Size() != 1 {
+		t.Errorf("a handle is still valid after Split()")
+	}
+
+	tree = newShuffledTree(10)
+	tree.SetJSONTypes(0, 0)
+	h = tree.Find(4)
+	if err := tree.UnmarshalJSON([]byte(`[{"key":4,"value":40}]`)); err != nil {
+		t.Fatal(err)
+	}
+	if h.Valid() {
+		t.Errorf("a handle is still valid after UnmarshalJSON()")
+	}
+
+	empty := NewWithIntComparator()
+	if empty.First().Valid() || empty.Last().Valid() {
+		t.Errorf("First() or Last() of an empty tree is valid")
+	}
+}
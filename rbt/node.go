@@ -1,7 +1,6 @@
 package rbt
 
 import (
-	"fmt"
 	"github.com/emirpasic/gods/utils"
 )
 
@@ -10,7 +9,7 @@ const RED = 1
 const LEFT = 2
 const RIGHT = 3
 
-// Node stores left, right, and parent Node pointers; the node's color,
+// Node stores left, right, and parent Node pointers; the node's color; the size of the node's subtree;
 // and NodeData, containing the key and the value the caller wishes to store.
 type Node struct {
 	left   *Node
@@ -18,6 +17,8 @@ type Node struct {
 	parent *Node
 	Data   *NodeData
 	color  int
+	size   int         // number of nodes in the subtree rooted at this node
+	agg    interface{} // aggregate of the subtree rooted at this node, if the tree has an Aggregate
 }
 
 // NodeData stores the key and the value of the Node.
@@ -38,55 +39,21 @@ func NewNode(k, v interface{}, color int) *Node {
 			Value: v,
 		},
 		color: color,
+		size:  1,
 	}
 }
 
-// dfs traverses the nodes in a depth-first search paradigm.
-// The function prints by converting each node's key and value to a string.
-func (node *Node) dfs() {
-	if node == nil {
-		return
-	}
-	if node.getParent() == nil {
-		fmt.Println("ROOT")
-	}
-	color := node.getColor()
-	switch {
-	case color == RED:
-		fmt.Println("Key = " + utils.ToString(node.Data.Key) +
-			" | " + "Value = " + utils.ToString(node.Data.Value) +
-			" | " + "Color = RED")
-	case color == BLACK:
-		fmt.Println("Key = " + utils.ToString(node.Data.Key) +
-			" | " + "Value = " + utils.ToString(node.Data.Value) +
-			" | " + "Color = BLACK")
+// describe returns the line the printing traversals write for the node:
+// its key and value converted to strings, and its color.
+func (node *Node) describe() string {
+	color := "BLACK"
+	if node.getColor() == RED {
+		color = "RED"
 	}
-	node.leftChild().dfs()
-	node.rightChild().dfs()
-}
 
-// inOrder traverses the nodes "in order," printing every node's value in order from smallest to greatest.
-// The function prints by converting each node's key and value to a string.
-func (node *Node) inOrder() {
-	if node == nil {
-		return
-	}
-	node.leftChild().inOrder()
-	if node.getParent() == nil {
-		fmt.Println("ROOT")
-	}
-	color := node.getColor()
-	switch {
-	case color == RED:
-		fmt.Println("Key = " + utils.ToString(node.Data.Key) +
-			" | " + "Value = " + utils.ToString(node.Data.Value) +
-			" | " + "Color = RED")
-	case color == BLACK:
-		fmt.Println("Key = " + utils.ToString(node.Data.Key) +
-			" | " + "Value = " + utils.ToString(node.Data.Value) +
-			" | " + "Color = BLACK")
-	}
-	node.rightChild().inOrder()
+	return "Key = " + utils.ToString(node.Data.Key) +
+		" | " + "Value = " + utils.ToString(node.Data.Value) +
+		" | " + "Color = " + color
 }
 
 // isRoot checks to see if Node's parent is nil and if its color is black.
@@ -256,6 +223,20 @@ func (node *Node) value() interface{} {
 	return nil
 }
 
+// subtreeSize returns the number of nodes in the subtree rooted at the node, or 0 if the node is nil.
+func (node *Node) subtreeSize() int {
+	if node != nil {
+		return node.size
+	}
+
+	return 0
+}
+
+// updateSize sets the node's subtree size from the sizes stored in its children.
+func (node *Node) updateSize() {
+	node.size = 1 + node.leftChild().subtreeSize() + node.rightChild().subtreeSize()
+}
+
 // successor returns the node with the smallest key greater than the node the method is called on
 func (node *Node) successor() *Node {
 	// successor is the furthest left child of the right subtree
@@ -276,11 +257,11 @@ func (node *Node) successor() *Node {
 
 // predecessor returns the node with the largest key smaller than the node the method is called on
 func (node *Node) predecessor() *Node {
-	// successor is the furthest left child of the right subtree
+	// predecessor is the furthest right child of the left subtree
 	if node.leftChild() != nil {
-		return node.subtreeMax(node.rightChild())
+		return node.subtreeMax(node.leftChild())
 	}
-	// otherwise, work up and to the right of the subtrees
+	// otherwise, work up and to the left of the subtrees
 	parent := node.getParent()
 	temp := node
 	for parent != nil && temp == parent.leftChild() {
@@ -330,3 +311,76 @@ func (node *Node) blackHeight() int {
 
 	return leftBlackHeight
 }
+
+// rootBlackHeight returns the number of black nodes on the path from the node down its left spine,
+// counting the node itself. In a valid red-black subtree every path has that many, so it takes O(log n).
+// Returns 0 if the node is nil.
+func (node *Node) rootBlackHeight() int {
+	height := 0
+	for temp := node; temp != nil; temp = temp.leftChild() {
+		if temp.getColor() == BLACK {
+			height++
+		}
+	}
+
+	return height
+}
+
+// Left returns the node's left child, or nil if the node has none or is nil.
+// Together with Right, Parent, Key, Value, and Color, it lets callers read the shape of a tree from Root.
+// The links, colors, and subtree sizes are read-only, since only the tree's methods recolor and rotate it.
+// The exported Data field is not: setting Data.Key through it breaks the key order, and setting Data.Value
+// leaves the aggregates of a tree from NewWithAggregate stale, so change entries with Update or Handle.SetValue.
+func (node *Node) Left() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.left
+}
+
+// Right returns the node's right child, or nil if the node has none or is nil.
+func (node *Node) Right() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.right
+}
+
+// Parent returns the node's parent, or nil if the node is the root or is nil.
+func (node *Node) Parent() *Node {
+	if node == nil {
+		return nil
+	}
+
+	return node.parent
+}
+
+// Key returns the node's key, or nil if the node is nil.
+func (node *Node) Key() interface{} {
+	if node == nil {
+		return nil
+	}
+
+	return node.Data.Key
+}
+
+// Value returns the node's value, or nil if the node is nil.
+func (node *Node) Value() interface{} {
+	if node == nil {
+		return nil
+	}
+
+	return node.Data.Value
+}
+
+// IsLeaf reports whether the node has no children. It returns false if the node is nil.
+func (node *Node) IsLeaf() bool {
+	return node != nil && node.left == nil && node.right == nil
+}
+
+// Color returns the node's color, RED or BLACK. A nil node is BLACK, like the nil leaves of the tree.
+func (node *Node) Color() int {
+	return node.getColor()
+}
@@ -0,0 +1,157 @@
+package rbt
+
+import (
+	"sync"
+)
+
+// parallelThreshold is the combined number of entries below which the Parallel set operations
+// stop spawning goroutines.
+const parallelThreshold = 4096
+
+// Union returns a tree holding every entry of a and of b, using a's comparator and aggregate.
+// When both trees hold a key, resolve receives the key, a's value, and b's value, and returns the value to keep;
+// if resolve is nil, b's value is kept.
+// Union splits a around the root of b and recurses on both halves, so it does O(m log(n/m + 1)) work
+// for trees of sizes m <= n, instead of m separate insertions.
+// Both a and b are left empty.
+func Union(a, b *RBT, resolve func(key, aValue, bValue interface{}) interface{}) *RBT {
+	return setOperation(a, b, func(scratch *RBT) subtree {
+		return scratch.union(a.whole(), b.whole(), resolve, false)
+	})
+}
+
+// ParallelUnion is like Union, except that it processes large disjoint subtrees in separate goroutines.
+// resolve may be called concurrently.
+func ParallelUnion(a, b *RBT, resolve func(key, aValue, bValue interface{}) interface{}) *RBT {
+	return setOperation(a, b, func(scratch *RBT) subtree {
+		return scratch.union(a.whole(), b.whole(), resolve, true)
+	})
+}
+
+// Intersection returns a tree holding the entries whose keys are in both a and b, using a's comparator and aggregate.
+// resolve receives each such key, a's value, and b's value, and returns the value to keep;
+// if resolve is nil, b's value is kept.
+// Like Union, it does O(m log(n/m + 1)) work.
+// Both a and b are left empty.
+func Intersection(a, b *RBT, resolve func(key, aValue, bValue interface{}) interface{}) *RBT {
+	return setOperation(a, b, func(scratch *RBT) subtree {
+		return scratch.intersection(a.whole(), b.whole(), resolve, false)
+	})
+}
+
+// ParallelIntersection is like Intersection, except that it processes large disjoint subtrees in separate goroutines.
+// resolve may be called concurrently.
+func ParallelIntersection(a, b *RBT, resolve func(key, aValue, bValue interface{}) interface{}) *RBT {
+	return setOperation(a, b, func(scratch *RBT) subtree {
+		return scratch.intersection(a.whole(), b.whole(), resolve, true)
+	})
+}
+
+// Difference returns a tree holding the entries of a whose keys are not in b, using a's comparator and aggregate.
+// No value ever comes from b, so there are no conflicts to resolve.
+// Like Union, it does O(m log(n/m + 1)) work.
+// Both a and b are left empty.
+func Difference(a, b *RBT) *RBT {
+	return setOperation(a, b, func(scratch *RBT) subtree {
+		return scratch.difference(a.whole(), b.whole(), false)
+	})
+}
+
+// ParallelDifference is like Difference, except that it processes large disjoint subtrees in separate goroutines.
+func ParallelDifference(a, b *RBT) *RBT {
+	return setOperation(a, b, func(scratch *RBT) subtree {
+		return scratch.difference(a.whole(), b.whole(), true)
+	})
+}
+
+// setOperation runs op on a scratch tree configured like a, wraps the root it returns in a new tree,
+// and empties a and b.
+// The root may be that of a fragment left by splitAt, which can be red, so it is colored black.
+func setOperation(a, b *RBT, op func(scratch *RBT) subtree) *RBT {
+	result := a.emptyCopy()
+	root := op(a.emptyCopy()).root
+	root.setColor(BLACK)
+	result.setRoot(root)
+	result.setSize(root.subtreeSize())
+	a.Clear()
+	b.Clear()
+
+	return result
+}
+
+// union merges the subtrees t1 and t2 and returns the merged subtree.
+func (tree *RBT) union(t1, t2 subtree, resolve func(key, aValue, bValue interface{}) interface{}, parallel bool) subtree {
+	if t1.root == nil {
+		return t2
+	}
+	if t2.root == nil {
+		return t1
+	}
+	size := t1.root.subtreeSize() + t2.root.subtreeSize()
+	mid, left2, right2 := tree.detach(t2)
+	left1, match, right1 := tree.splitAt(t1, mid.key())
+	if match != nil && resolve != nil {
+		mid.setValue(resolve(mid.key(), match.value(), mid.value()))
+	}
+	left, right := tree.recurse(size, parallel,
+		func(scratch *RBT) subtree { return scratch.union(left1, left2, resolve, parallel) },
+		func(scratch *RBT) subtree { return scratch.union(right1, right2, resolve, parallel) })
+
+	return tree.join(left, mid, right)
+}
+
+// intersection keeps the nodes of t2 whose keys are also under t1 and returns the resulting subtree.
+func (tree *RBT) intersection(t1, t2 subtree, resolve func(key, aValue, bValue interface{}) interface{}, parallel bool) subtree {
+	if t1.root == nil || t2.root == nil {
+		return subtree{}
+	}
+	size := t1.root.subtreeSize() + t2.root.subtreeSize()
+	mid, left2, right2 := tree.detach(t2)
+	left1, match, right1 := tree.splitAt(t1, mid.key())
+	left, right := tree.recurse(size, parallel,
+		func(scratch *RBT) subtree { return scratch.intersection(left1, left2, resolve, parallel) },
+		func(scratch *RBT) subtree { return scratch.intersection(right1, right2, resolve, parallel) })
+	if match == nil {
+		return tree.join2(left, right)
+	}
+	if resolve != nil {
+		mid.setValue(resolve(mid.key(), match.value(), mid.value()))
+	}
+
+	return tree.join(left, mid, right)
+}
+
+// difference removes from t1 the keys under t2 and returns the resulting subtree.
+func (tree *RBT) difference(t1, t2 subtree, parallel bool) subtree {
+	if t1.root == nil || t2.root == nil {
+		return t1
+	}
+	size := t1.root.subtreeSize() + t2.root.subtreeSize()
+	mid, left2, right2 := tree.detach(t2)
+	left1, _, right1 := tree.splitAt(t1, mid.key())
+	left, right := tree.recurse(size, parallel,
+		func(scratch *RBT) subtree { return scratch.difference(left1, left2, parallel) },
+		func(scratch *RBT) subtree { return scratch.difference(right1, right2, parallel) })
+
+	return tree.join2(left, right)
+}
+
+// recurse runs the two halves of a set operation and returns their results.
+// If parallel is set and the operation spans at least parallelThreshold entries,
+// the left half runs in its own goroutine with its own scratch tree.
+func (tree *RBT) recurse(size int, parallel bool, left, right func(scratch *RBT) subtree) (subtree, subtree) {
+	if !parallel || size < parallelThreshold {
+		return left(tree), right(tree)
+	}
+	var leftResult subtree
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		leftResult = left(tree.emptyCopy())
+	}()
+	rightResult := right(tree)
+	wg.Wait()
+
+	return leftResult, rightResult
+}
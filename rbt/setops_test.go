@@ -0,0 +1,158 @@
+package rbt
+
+import (
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"testing"
+)
+
+// randomTree returns a tree of n distinct keys drawn from [0, keySpace), each mapped to tag,
+// and the keys it holds.
+func randomTree(r *rand.Rand, n, keySpace int, tag string) (*RBT, map[int]bool) {
+	tree := NewWithIntComparator()
+	keys := make(map[int]bool)
+	for _, key := range r.Perm(keySpace)[:n] {
+		tree.Insert(key, tag)
+		keys[key] = true
+	}
+
+	return tree, keys
+}
+
+// checkSetResult fails the test unless tree holds exactly the keys for which keep returns true,
+// each mapped to the value wantValue returns, and keeps its invariants.
+func checkSetResult(t *testing.T, tree *RBT, keySpace int, keep func(key int) bool, wantValue func(key int) interface{}) {
+	t.Helper()
+	var want []int
+	for key := 0; key < keySpace; key++ {
+		if keep(key) {
+			want = append(want, key)
+		}
+	}
+	checkTree(t, tree, want)
+	for _, key := range want {
+		if got, _ := tree.ReturnNodeValue(key); got != wantValue(key) {
+			t.Fatalf("value of %d = %v, want %v", key, got, wantValue(key))
+		}
+	}
+}
+
+// concat resolves conflicts by joining both values, so tests can see which side each came from.
+func concat(key, aValue, bValue interface{}) interface{} {
+	return aValue.(string) + bValue.(string)
+}
+
+func TestSetOperations(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, sizes := range [][2]int{{0, 0}, {0, 50}, {50, 0}, {1, 1}, {300, 300}, {5, 900}, {900, 5}, {1000, 1000}} {
+		const keySpace = 2000
+		for _, parallel := range []bool{false, true} {
+			a, inA := randomTree(r, sizes[0], keySpace, "a")
+			b, inB := randomTree(r, sizes[1], keySpace, "b")
+			var union *RBT
+			if parallel {
+				union = ParallelUnion(a, b, concat)
+			} else {
+				union = Union(a, b, concat)
+			}
+			checkSetResult(t, union, keySpace,
+				func(key int) bool { return inA[key] || inB[key] },
+				func(key int) interface{} {
+					switch {
+					case inA[key] && inB[key]:
+						return "ab"
+					case inA[key]:
+						return "a"
+					default:
+						return "b"
+					}
+				})
+			if !a.IsEmpty() || !b.IsEmpty() {
+				t.Errorf("Union() left its inputs non-empty")
+			}
+
+			a, inA = randomTree(r, sizes[0], keySpace, "a")
+			b, inB = randomTree(r, sizes[1], keySpace, "b")
+			var intersection *RBT
+			if parallel {
+				intersection = ParallelIntersection(a, b, concat)
+			} else {
+				intersection = Intersection(a, b, concat)
+			}
+			checkSetResult(t, intersection, keySpace,
+				func(key int) bool { return inA[key] && inB[key] },
+				func(key int) interface{} { return "ab" })
+
+			a, inA = randomTree(r, sizes[0], keySpace, "a")
+			b, inB = randomTree(r, sizes[1], keySpace, "b")
+			var difference *RBT
+			if parallel {
+				difference = ParallelDifference(a, b)
+			} else {
+				difference = Difference(a, b)
+			}
+			checkSetResult(t, difference, keySpace,
+				func(key int) bool { return inA[key] && !inB[key] },
+				func(key int) interface{} { return "a" })
+		}
+	}
+}
+
+func TestParallelUnion_Large(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	const keySpace = 200000
+	a, inA := randomTree(r, 60000, keySpace, "a")
+	b, inB := randomTree(r, 60000, keySpace, "b")
+	union := ParallelUnion(a, b, nil)
+	checkSetResult(t, union, keySpace,
+		func(key int) bool { return inA[key] || inB[key] },
+		func(key int) interface{} {
+			if inB[key] {
+				return "b"
+			}
+			return "a"
+		})
+}
+
+func TestUnion_KeepsAggregates(t *testing.T) {
+	a, b := NewWithAggregate(utils.IntComparator, sumOfValues), NewWithAggregate(utils.IntComparator, sumOfValues)
+	for key := 0; key < 100; key++ {
+		a.Insert(key, 1)
+		b.Insert(key+50, 2)
+	}
+	tree := Union(a, b, func(key, aValue, bValue interface{}) interface{} { return aValue.(int) + bValue.(int) })
+	checkRBT(t, tree)
+	if got := tree.AggregateRange(allKeys, allKeys); got != 50*1+50*3+50*2 {
+		t.Errorf("sum after Union = %v, want %d", got, 50*1+50*3+50*2)
+	}
+}
+
+func TestSetOperations_SmallTrees(t *testing.T) {
+	// small trees split into fragments with red roots often, and a fragment can be the whole result
+	const keySpace = 40
+	r := rand.New(rand.NewSource(1))
+	operations := []struct {
+		name string
+		op   func(a, b *RBT) *RBT
+		keep func(inA, inB bool) bool
+	}{
+		{"Union", func(a, b *RBT) *RBT { return Union(a, b, nil) }, func(inA, inB bool) bool { return inA || inB }},
+		{"Intersection", func(a, b *RBT) *RBT { return Intersection(a, b, nil) }, func(inA, inB bool) bool { return inA && inB }},
+		{"Difference", Difference, func(inA, inB bool) bool { return inA && !inB }},
+	}
+	for trial := 0; trial < 3000; trial++ {
+		for _, o := range operations {
+			a, inA := randomTree(r, r.Intn(keySpace), keySpace, "a")
+			b, inB := randomTree(r, r.Intn(keySpace), keySpace, "b")
+			result := o.op(a, b)
+			if err := result.Validate(); err != nil {
+				t.Fatalf("trial %d: %s() is invalid: %v", trial, o.name, err)
+			}
+			for key := 0; key < keySpace; key++ {
+				if result.Search(key) != o.keep(inA[key], inB[key]) {
+					t.Fatalf("trial %d: %s() has the wrong entry for %d", trial, o.name, key)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package rbt
+
+// Split divides the tree around key and returns two new trees:
+// the first holds every entry whose key is smaller than key, and the second every other entry.
+// The tree Split is called on is left empty.
+// Split rebuilds the two halves by joining subtrees along the search path for key.
+// It tracks the black height of every subtree on the way down instead of recounting it,
+// and each join costs O(1) plus the difference of the black heights it joins, which add up to O(log n).
+func (tree *RBT) Split(key interface{}) (*RBT, *RBT) {
+	lessPart, restPart := tree.split(tree.whole(), key)
+	less, rest := tree.emptyCopy(), tree.emptyCopy()
+	less.setRoot(lessPart.root)
+	less.setSize(lessPart.root.subtreeSize())
+	rest.setRoot(restPart.root)
+	rest.setSize(restPart.root.subtreeSize())
+	tree.Clear()
+
+	return less, rest
+}
+
+// Join concatenates two trees and returns the result, which uses left's comparator and aggregate.
+// Every key of left must be smaller than every key of right; otherwise Join returns an OverlapError
+// and leaves both trees untouched.
+// On success, left and right are left empty.
+// Join counts the black height of both trees once, then links the tree with the smaller black height
+// into the spine of the other, so it runs in O(log n).
+func Join(left, right *RBT) (*RBT, error) {
+	if !left.IsEmpty() && !right.IsEmpty() &&
+		left.comparator(left.maxNode().key(), right.minNode().key()) >= 0 {
+		return nil, NewOverlapError(right.minNode().key())
+	}
+	joined := left.emptyCopy()
+	joined.setRoot(joined.join2(left.whole(), right.whole()).root)
+	joined.setSize(left.Size() + right.Size())
+	left.Clear()
+	right.Clear()
+
+	return joined, nil
+}
+
+// emptyCopy returns a pointer to an empty RBT with the same configuration as the tree.
+func (tree *RBT) emptyCopy() *RBT {
+	return &RBT{
+		root:       nil,
+		comparator: tree.comparator,
+		size:       0,
+		aggregate:  tree.aggregate,
+		keyType:    tree.keyType,
+		valueType:  tree.valueType,
+		keyCodec:   tree.keyCodec,
+		valueCodec: tree.valueCodec,
+	}
+}
+
+// subtree is the root of a red-black subtree together with its black height, as rootBlackHeight counts it.
+// split, join, and the set operations pass black heights along instead of recounting them,
+// since recounting takes O(log n) on every join.
+type subtree struct {
+	root        *Node
+	blackHeight int
+}
+
+// whole returns the tree's root as a subtree, counting its black height once.
+func (tree *RBT) whole() subtree {
+	return subtree{root: tree.Root(), blackHeight: tree.Root().rootBlackHeight()}
+}
+
+// detach severs the root of t from its children and returns the root and its former children.
+// Every path through a child has the same black nodes as through the root, except the root itself.
+func (tree *RBT) detach(t subtree) (*Node, subtree, subtree) {
+	node := t.root
+	childBlackHeight := t.blackHeight
+	if node.getColor() == BLACK {
+		childBlackHeight--
+	}
+	left := subtree{root: node.leftChild(), blackHeight: childBlackHeight}
+	right := subtree{root: node.rightChild(), blackHeight: childBlackHeight}
+	left.root.setParent(nil)
+	right.root.setParent(nil)
+	node.clear()
+
+	return node, left, right
+}
+
+// split divides t into the nodes whose keys are smaller than key and the rest,
+// returning the two rebuilt subtrees.
+// The tree's root is used as scratch space.
+func (tree *RBT) split(t subtree, key interface{}) (subtree, subtree) {
+	if t.root == nil {
+		return subtree{}, subtree{}
+	}
+	node, left, right := tree.detach(t)
+	if tree.comparator(node.key(), key) < 0 { // node and its left subtree are smaller than key
+		less, rest := tree.split(right, key)
+		return tree.join(left, node, less), rest
+	}
+	less, rest := tree.split(left, key)
+
+	return less, tree.join(rest, node, right)
+}
+
+// splitAt divides t into the nodes whose keys are smaller than key,
+// the node whose key equals key, if any, and the nodes whose keys are greater than key.
+// It returns the two rebuilt subtrees and the detached matching node, or nil.
+// The roots of the rebuilt subtrees may be red.
+// The tree's root is used as scratch space.
+func (tree *RBT) splitAt(t subtree, key interface{}) (subtree, *Node, subtree) {
+	if t.root == nil {
+		return subtree{}, nil, subtree{}
+	}
+	node, left, right := tree.detach(t)
+	compare := tree.comparator(node.key(), key)
+	switch {
+	case compare < 0:
+		less, match, greater := tree.splitAt(right, key)
+		return tree.join(left, node, less), match, greater
+	case compare > 0:
+		less, match, greater := tree.splitAt(left, key)
+		return less, match, tree.join(greater, node, right)
+	default:
+		return left, node, right
+	}
+}
+
+// splitLast removes the node with the largest key from t, which must not be empty,
+// and returns the rebuilt rest of t and the removed node.
+// The tree's root is used as scratch space.
+func (tree *RBT) splitLast(t subtree) (subtree, *Node) {
+	node, left, right := tree.detach(t)
+	if right.root == nil {
+		return left, node
+	}
+	rest, last := tree.splitLast(right)
+
+	return tree.join(left, node, rest), last
+}
+
+// join links the subtrees left and right under mid and restores the red-black invariants,
+// returning the new subtree.
+// Every key under left must be smaller than mid's key, which must be smaller than every key under right.
+// It walks down only as many black levels as the black heights of left and right differ by.
+// The tree's root is used as scratch space.
+func (tree *RBT) join(left subtree, mid *Node, right subtree) subtree {
+	// a red root may always be recolored black, which keeps the subtree valid and adds one to its black height
+	if left.root.getColor() == RED {
+		left.root.setColor(BLACK)
+		left.blackHeight++
+	}
+	if right.root.getColor() == RED {
+		right.root.setColor(BLACK)
+		right.blackHeight++
+	}
+
+	var parent *Node
+	joined := subtree{}
+	switch {
+	case left.blackHeight > right.blackHeight:
+		// descend the right spine of left to a black subtree with right's black height
+		tree.setRoot(left.root)
+		spine, height := left.root, left.blackHeight
+		for spine.getColor() == RED || height > right.blackHeight {
+			if spine.getColor() == BLACK {
+				height--
+			}
+			parent = spine
+			spine = spine.rightChild()
+		}
+		parent.setRightChild(mid)
+		tree.link(mid, spine, right.root)
+		joined.blackHeight = left.blackHeight
+	case right.blackHeight > left.blackHeight:
+		// descend the left spine of right to a black subtree with left's black height
+		tree.setRoot(right.root)
+		spine, height := right.root, right.blackHeight
+		for spine.getColor() == RED || height > left.blackHeight {
+			if spine.getColor() == BLACK {
+				height--
+			}
+			parent = spine
+			spine = spine.leftChild()
+		}
+		parent.setLeftChild(mid)
+		tree.link(mid, left.root, spine)
+		joined.blackHeight = right.blackHeight
+	default:
+		tree.setRoot(mid)
+		tree.link(mid, left.root, right.root)
+		mid.setColor(BLACK)
+		tree.refresh(mid)
+		return subtree{root: mid, blackHeight: left.blackHeight + 1}
+	}
+	mid.setParent(parent)
+	mid.setColor(RED)
+	tree.refreshPath(mid)
+	if tree.insertFixup(mid) {
+		joined.blackHeight++
+	}
+	joined.root = tree.Root()
+
+	return joined
+}
+
+// link sets left and right as mid's children.
+func (tree *RBT) link(mid, left, right *Node) {
+	mid.setLeftChild(left)
+	left.setParent(mid)
+	mid.setRightChild(right)
+	right.setParent(mid)
+}
+
+// join2 concatenates the subtrees left and right, returning the new subtree.
+// Every key under left must be smaller than every key under right.
+// The largest node of left is split off and used as the middle node of a join.
+func (tree *RBT) join2(left, right subtree) subtree {
+	if left.root == nil {
+		return right
+	}
+	if right.root == nil {
+		return left
+	}
+	rest, last := tree.splitLast(left)
+
+	return tree.join(rest, last, right)
+}
@@ -2,7 +2,10 @@ package rbt
 
 import (
 	"fmt"
+	"github.com/chancetudor/trees"
 	"github.com/emirpasic/gods/utils"
+	"io"
+	"reflect"
 )
 
 /* Package rbt implements a red-black tree in Go
@@ -22,6 +25,12 @@ type RBT struct {
 	root       *Node            // the root Node
 	comparator utils.Comparator // the key comparator
 	size       int              // number of nodes in the tree
+	keyType    reflect.Type     // the type UnmarshalJSON decodes keys into, or nil
+	valueType  reflect.Type     // the type UnmarshalJSON decodes values into, or nil
+	keyCodec   trees.Codec      // the Codec MarshalBinary encodes keys with, or nil
+	valueCodec trees.Codec      // the Codec MarshalBinary encodes values with, or nil
+	aggregate  trees.Aggregate  // the subtree aggregate stored in every node, or nil
+	epoch      int              // incremented whenever every entry is replaced at once, which invalidates every Handle
 }
 
 // NewWith returns a pointer to a RBT where root is nil, size is 0,
@@ -51,25 +60,48 @@ func NewWithStringComparator() *RBT {
 	return NewWith(utils.StringComparator)
 }
 
-// DepthFirstTraversal (pre-order traversal) traverses the binary search tree by printing the root node,
-// then recursively visiting the left and the right nodes of the current node.
-func (tree *RBT) DepthFirstTraversal() {
-	if !tree.IsEmpty() {
-		tree.Root().dfs()
-		return
-	}
+// NewWithAggregate returns a pointer to a RBT like NewWith does,
+// except that every node also stores the aggregate of its subtree, which AggregateRange uses.
+// The library keeps the aggregates current through every insertion, deletion, update, and rotation.
+func NewWithAggregate(comparator utils.Comparator, aggregate trees.Aggregate) *RBT {
+	tree := NewWith(comparator)
+	tree.aggregate = aggregate
+
+	return tree
+}
+
+// DepthFirstTraversal (pre-order traversal) writes one line per node to w, visiting the root node first,
+// then the left and the right subtrees of each node. The root's line is preceded by a "ROOT" line.
+// The function returns the first error w returned, if there was one.
+func (tree *RBT) DepthFirstTraversal(w io.Writer) error {
+	return tree.print(w, trees.PreOrder)
+}
 
-	fmt.Println("Empty tree: []")
+// InOrderTraversal writes one line per node to w, in order from smallest to greatest key.
+// The root's line is preceded by a "ROOT" line.
+// The function returns the first error w returned, if there was one.
+func (tree *RBT) InOrderTraversal(w io.Writer) error {
+	return tree.print(w, trees.InOrder)
 }
 
-// InOrderTraversal prints every node's value in order from smallest to greatest.
-func (tree *RBT) InOrderTraversal() {
-	if !tree.IsEmpty() {
-		tree.Root().inOrder()
-		return
+// print writes every node's description to w in the given order, stopping at the first write error.
+func (tree *RBT) print(w io.Writer, order trees.TraversalOrder) error {
+	if tree.IsEmpty() {
+		_, err := fmt.Fprintln(w, "Empty tree: []")
+		return err
 	}
+	var err error
+	tree.walk(order, func(node *Node) bool {
+		if node.isRoot() {
+			if _, err = fmt.Fprintln(w, "ROOT"); err != nil {
+				return false
+			}
+		}
+		_, err = fmt.Fprintln(w, node.describe())
+		return err == nil
+	})
 
-	fmt.Println("Empty tree: []")
+	return err
 }
 
 // Insert takes a key and a value of type interface, and inserts a new Node with that key and value.
@@ -104,6 +136,7 @@ func (tree *RBT) Insert(key, value interface{}) (interface{}, error) {
 		parent.setRightChild(newNode)
 	}
 	newNode.setColor(RED)
+	tree.refreshPath(newNode)
 	tree.insertFixup(newNode)
 	tree.setSize(tree.Size() + 1)
 
@@ -117,7 +150,9 @@ func (tree *RBT) Insert(key, value interface{}) (interface{}, error) {
 // the opposite direction of newNode's placement.
 // 3. newNode's uncle is black (line): rotate node's grandparent in
 // the opposite direction of newNode's placement, then recolor original parent and grandparent.
-func (tree *RBT) insertFixup(node *Node) {
+// The function reports whether it recolored a red root black at the end,
+// which adds one to the black height of the whole tree; join uses this to track black heights.
+func (tree *RBT) insertFixup(node *Node) bool {
 	for node.getParent().getColor() == RED {
 		if node.getParent() == node.grandparent().leftChild() {
 			uncle := node.grandparent().rightChild()
@@ -151,7 +186,11 @@ func (tree *RBT) insertFixup(node *Node) {
 			}
 		}
 	}
-	tree.Root().setColor(BLACK)
+	root := tree.Root()
+	grew := root.getColor() == RED
+	root.setColor(BLACK)
+
+	return grew
 }
 
 // Delete takes a key, removes the node from the tree, and decrements the size of the tree.
@@ -163,43 +202,50 @@ func (tree *RBT) Delete(key interface{}) (interface{}, error) {
 		return nil, err
 	}
 	nodeToDeleteKey := nodeToDelete.key()
+	tree.deleteNode(nodeToDelete)
 
-	var sibling, successor *Node
-	if nodeToDelete.leftChild() != nil && nodeToDelete.rightChild() != nil {
-		successor = nodeToDelete.successor()
-	} else {
-		successor = nodeToDelete
-	}
-
-	if successor.leftChild() != nil {
-		sibling = successor.leftChild()
-	} else {
-		sibling = successor.rightChild()
-	}
-
-	newParent := successor.getParent()
-	if sibling != nil {
-		sibling.setParent(newParent)
-	}
-	if successor.getParent() == nil {
-		tree.setRoot(sibling)
-	} else if successor == successor.getParent().leftChild() {
-		successor.getParent().setLeftChild(sibling)
-	} else {
-		successor.getParent().setRightChild(sibling)
-	}
+	return nodeToDeleteKey, nil
+}
 
-	if successor != nodeToDelete {
-		nodeToDelete.setKey(successor.key())
-		nodeToDelete.setValue(successor.value())
+// deleteNode removes a node from the tree, restores the red-black invariants,
+// and decrements the size of the tree.
+// The node is unlinked rather than overwritten with its successor's entry,
+// so every other node, and every Handle to it, keeps its entry.
+func (tree *RBT) deleteNode(nodeToDelete *Node) {
+	// x is the node that moves into the removed node's place; it may be nil, so track its parent too.
+	var x, xParent *Node
+	removedColor := nodeToDelete.getColor()
+	switch {
+	case nodeToDelete.leftChild() == nil:
+		x, xParent = nodeToDelete.rightChild(), nodeToDelete.getParent()
+		tree.replaceSubTree(nodeToDelete, x)
+	case nodeToDelete.rightChild() == nil:
+		x, xParent = nodeToDelete.leftChild(), nodeToDelete.getParent()
+		tree.replaceSubTree(nodeToDelete, x)
+	default: // the node to delete has two subtrees
+		successor := nodeToDelete.rightChild().subtreeMin()
+		removedColor = successor.getColor()
+		x = successor.rightChild()
+		if successor.getParent() == nodeToDelete {
+			xParent = successor
+		} else {
+			xParent = successor.getParent()
+			tree.replaceSubTree(successor, x)
+			successor.setRightChild(nodeToDelete.rightChild())
+			successor.rightChild().setParent(successor)
+		}
+		tree.replaceSubTree(nodeToDelete, successor)
+		successor.setLeftChild(nodeToDelete.leftChild())
+		successor.leftChild().setParent(successor)
+		successor.setColor(nodeToDelete.getColor())
 	}
-
-	if successor.getColor() == BLACK {
-		tree.deleteFixup(sibling, newParent)
+	nodeToDelete.clear()
+	nodeToDelete.size = 0 // marks the node as deleted for its Handles
+	tree.refreshPath(xParent)
+	if removedColor == BLACK {
+		tree.deleteFixup(x, xParent)
 	}
 	tree.setSize(tree.Size() - 1)
-
-	return nodeToDeleteKey, nil
 }
 
 // replaceSubTree replaces one subtree as a child of its parent with
@@ -390,6 +436,117 @@ func (tree *RBT) ReturnNodeValue(key interface{}) (interface{}, error) {
 	return matchingNode.value(), nil
 }
 
+// Floor takes a key and returns the entry with the largest key less than or equal to it.
+// The boolean is false if no such entry exists.
+func (tree *RBT) Floor(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.floorNode(key))
+}
+
+// Ceiling takes a key and returns the entry with the smallest key greater than or equal to it.
+// The boolean is false if no such entry exists.
+func (tree *RBT) Ceiling(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.ceilingNode(key))
+}
+
+// Lower takes a key and returns the entry with the largest key strictly less than it.
+// The boolean is false if no such entry exists.
+func (tree *RBT) Lower(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.lowerNode(key))
+}
+
+// Higher takes a key and returns the entry with the smallest key strictly greater than it.
+// The boolean is false if no such entry exists.
+func (tree *RBT) Higher(key interface{}) (interface{}, interface{}, bool) {
+	return entryOf(tree.higherNode(key))
+}
+
+// Min returns the entry with the smallest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *RBT) Min() (interface{}, interface{}, bool) {
+	return entryOf(tree.minNode())
+}
+
+// Max returns the entry with the largest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *RBT) Max() (interface{}, interface{}, bool) {
+	return entryOf(tree.maxNode())
+}
+
+// PopMin removes and returns the entry with the smallest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *RBT) PopMin() (interface{}, interface{}, bool) {
+	return tree.pop(tree.minNode())
+}
+
+// PopMax removes and returns the entry with the largest key in the tree.
+// The boolean is false if the tree is empty.
+func (tree *RBT) PopMax() (interface{}, interface{}, bool) {
+	return tree.pop(tree.maxNode())
+}
+
+// Select takes an index i and returns the entry with the i-th smallest key, counting from 0.
+// The boolean is false if i is negative or not less than the size of the tree.
+func (tree *RBT) Select(i int) (interface{}, interface{}, bool) {
+	if i < 0 || i >= tree.Size() {
+		return nil, nil, false
+	}
+	tempNode := tree.Root()
+	for tempNode != nil {
+		leftSize := tempNode.leftChild().subtreeSize()
+		switch {
+		case i < leftSize:
+			tempNode = tempNode.leftChild()
+		case i > leftSize:
+			i -= leftSize + 1
+			tempNode = tempNode.rightChild()
+		default:
+			return entryOf(tempNode)
+		}
+	}
+
+	return nil, nil, false
+}
+
+// Rank takes a key and returns the number of keys in the tree that are smaller than it.
+// The key does not need to be in the tree.
+func (tree *RBT) Rank(key interface{}) int {
+	rank := 0
+	tempNode := tree.Root()
+	for tempNode != nil {
+		compare := tree.comparator(key, tempNode.key())
+		switch {
+		case compare < 0:
+			tempNode = tempNode.leftChild()
+		case compare > 0:
+			rank += tempNode.leftChild().subtreeSize() + 1
+			tempNode = tempNode.rightChild()
+		default:
+			return rank + tempNode.leftChild().subtreeSize()
+		}
+	}
+
+	return rank
+}
+
+// pop removes a node from the tree and returns its entry, or nil, nil, and false if the node is nil.
+func (tree *RBT) pop(node *Node) (interface{}, interface{}, bool) {
+	key, value, found := entryOf(node)
+	if found {
+		tree.deleteNode(node)
+	}
+
+	return key, value, found
+}
+
+// entryOf returns the key and value of a node and true, or nil, nil, and false if the node is nil.
+func entryOf(node *Node) (interface{}, interface{}, bool) {
+	if node == nil {
+		return nil, nil, false
+	}
+
+	return node.key(), node.value(), true
+}
+
 // Update takes a key and a value and updates a node with the existing key with the new value.
 // Returns the new value of the node or an error, if there was one.
 func (tree *RBT) Update(key interface{}, value interface{}) (interface{}, error) {
@@ -398,14 +555,19 @@ func (tree *RBT) Update(key interface{}, value interface{}) (interface{}, error)
 		return nil, err
 	}
 	matchingNode.setValue(value)
+	if tree.aggregate != nil {
+		tree.refreshPath(matchingNode)
+	}
 
 	return matchingNode.value(), nil
 }
 
 // Clear sets the root node to nil and sets the size of the tree to 0.
+// Every Handle to the tree becomes invalid.
 func (tree *RBT) Clear() {
 	tree.setRoot(nil)
 	tree.setSize(0)
+	tree.epoch++
 }
 
 // Root returns the root of the tree, a pointer to type Node.
@@ -449,6 +611,8 @@ func (tree *RBT) leftRotate(node *Node) {
 	}
 	newParent.setLeftChild(node)
 	node.setParent(newParent)
+	tree.refresh(node)
+	tree.refresh(newParent)
 }
 
 // rightRotate performs right rotations on the nodes
@@ -477,6 +641,8 @@ func (tree *RBT) rightRotate(node *Node) {
 	}
 	newParent.setRightChild(node)
 	node.setParent(newParent)
+	tree.refresh(node)
+	tree.refresh(newParent)
 }
 
 // findNode takes a key and returns the node associated with that key.
@@ -497,6 +663,100 @@ func (tree *RBT) findNode(key interface{}) (*Node, error) {
 	return nil, NewNilNodeError(key)
 }
 
+// ceilingNode takes a key and returns the node with the smallest key greater than or equal to it.
+// Returns nil if no such node exists.
+func (tree *RBT) ceilingNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		compare := tree.comparator(key, tempNode.key())
+		switch {
+		case compare < 0:
+			candidate = tempNode
+			tempNode = tempNode.leftChild()
+		case compare > 0:
+			tempNode = tempNode.rightChild()
+		case compare == 0:
+			return tempNode
+		}
+	}
+
+	return candidate
+}
+
+// floorNode takes a key and returns the node with the largest key less than or equal to it.
+// Returns nil if no such node exists.
+func (tree *RBT) floorNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		compare := tree.comparator(key, tempNode.key())
+		switch {
+		case compare < 0:
+			tempNode = tempNode.leftChild()
+		case compare > 0:
+			candidate = tempNode
+			tempNode = tempNode.rightChild()
+		case compare == 0:
+			return tempNode
+		}
+	}
+
+	return candidate
+}
+
+// lowerNode takes a key and returns the node with the largest key strictly less than it.
+// Returns nil if no such node exists.
+func (tree *RBT) lowerNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		if tree.comparator(key, tempNode.key()) > 0 {
+			candidate = tempNode
+			tempNode = tempNode.rightChild()
+		} else {
+			tempNode = tempNode.leftChild()
+		}
+	}
+
+	return candidate
+}
+
+// higherNode takes a key and returns the node with the smallest key strictly greater than it.
+// Returns nil if no such node exists.
+func (tree *RBT) higherNode(key interface{}) *Node {
+	var candidate *Node
+	tempNode := tree.Root()
+	for tempNode != nil {
+		if tree.comparator(key, tempNode.key()) < 0 {
+			candidate = tempNode
+			tempNode = tempNode.leftChild()
+		} else {
+			tempNode = tempNode.rightChild()
+		}
+	}
+
+	return candidate
+}
+
+// minNode returns the node with the smallest key in the tree, or nil if the tree is empty.
+func (tree *RBT) minNode() *Node {
+	if tree.IsEmpty() {
+		return nil
+	}
+
+	return tree.Root().subtreeMin()
+}
+
+// maxNode returns the node with the largest key in the tree, or nil if the tree is empty.
+func (tree *RBT) maxNode() *Node {
+	if tree.IsEmpty() {
+		return nil
+	}
+
+	return tree.Root().subtreeMax(tree.Root())
+}
+
 // setSize sets a new size, or number of nodes in the tree, for the tree.
 func (tree *RBT) setSize(newSize int) {
 	tree.size = newSize
@@ -506,3 +766,21 @@ func (tree *RBT) setSize(newSize int) {
 func (tree *RBT) setRoot(newRoot *Node) {
 	tree.root = newRoot
 }
+
+// refresh recomputes the subtree size of a node and, if the tree has an Aggregate,
+// the subtree aggregate of the node, from the values stored in its children.
+func (tree *RBT) refresh(node *Node) {
+	node.updateSize()
+	if tree.aggregate != nil {
+		node.agg = tree.aggregate.Combine(
+			tree.aggregate.Combine(tree.subtreeAggregate(node.leftChild()), tree.lift(node)),
+			tree.subtreeAggregate(node.rightChild()))
+	}
+}
+
+// refreshPath refreshes a node and every ancestor up to the root.
+func (tree *RBT) refreshPath(node *Node) {
+	for temp := node; temp != nil; temp = temp.getParent() {
+		tree.refresh(temp)
+	}
+}
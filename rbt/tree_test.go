@@ -2,18 +2,21 @@ package rbt
 
 import (
 	"fmt"
+	"github.com/chancetudor/trees"
+	"github.com/chancetudor/trees/treetest"
+	"github.com/emirpasic/gods/utils"
 	"math/rand"
 	"reflect"
 	"strconv"
+	"strings"
 	"testing"
-	"time"
 )
 
 func TestRBT_Insert(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	tree := NewWithIntComparator()
-	rand.Seed(time.Now().UnixNano())
 	for i := 0; i < 100; i++ {
-		key := rand.Int()
+		key := rng.Int()
 		got, err := tree.Insert(key, i)
 		if err != nil {
 			t.Errorf("Insert() error = %v", err)
@@ -30,12 +33,12 @@ func TestRBT_Insert(t *testing.T) {
 }
 
 func TestRBT_Delete(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	tree := NewWithIntComparator()
-	rand.Seed(time.Now().UnixNano())
 	keyVals := make(map[interface{}]int)
 	size := 100
 	for i := 0; i < size; i++ {
-		key := rand.Int()
+		key := rng.Int()
 		got, err := tree.Insert(key, i)
 		keyVals[got] = i
 		if err != nil {
@@ -61,15 +64,18 @@ func TestRBT_Delete(t *testing.T) {
 		t.Errorf("Failed deletion")
 	}
 
-	tree.InOrderTraversal()
+	var out strings.Builder
+	if err := tree.InOrderTraversal(&out); err != nil || out.String() != "Empty tree: []\n" {
+		t.Errorf("InOrderTraversal() of an empty tree wrote %q, %v", out.String(), err)
+	}
 }
 
 func TestRBT_Search(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	tree := NewWithIntComparator()
-	rand.Seed(time.Now().UnixNano())
 	keyVals := make(map[interface{}]int)
 	for i := 0; i < 100; i++ {
-		key := rand.Int()
+		key := rng.Int()
 		got, err := tree.Insert(key, i)
 		keyVals[got] = i
 		if err != nil {
@@ -89,11 +95,11 @@ func TestRBT_Search(t *testing.T) {
 }
 
 func TestRBT_ReturnNodeValue(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	tree := NewWithIntComparator()
-	rand.Seed(time.Now().UnixNano())
 	keyVals := make(map[interface{}]int)
 	for i := 0; i < 100; i++ {
-		key := rand.Int()
+		key := rng.Int()
 		got, err := tree.Insert(key, i)
 		keyVals[got] = i
 		if err != nil {
@@ -114,9 +120,10 @@ func TestRBT_ReturnNodeValue(t *testing.T) {
 }
 
 func TestRBT_DepthFirstTraversal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	tree := NewWithIntComparator()
 	for i := 0; i < 100; i++ {
-		key := rand.Int()
+		key := rng.Int()
 		_, err := tree.Insert(key, i)
 		if err != nil {
 			t.Errorf(err.Error())
@@ -125,14 +132,21 @@ func TestRBT_DepthFirstTraversal(t *testing.T) {
 	if !tree.IsBalanced() && tree.Size() != 100 {
 		t.Errorf("Tree is not balanced")
 	}
-	tree.DepthFirstTraversal()
+	var out strings.Builder
+	if err := tree.DepthFirstTraversal(&out); err != nil {
+		t.Fatalf("DepthFirstTraversal() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != tree.Size()+1 || strings.Count(out.String(), "ROOT\n") != 1 {
+		t.Errorf("DepthFirstTraversal() wrote %d lines for %d nodes", len(lines), tree.Size())
+	}
 }
 
 func TestRBT_InOrderTraversal(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	tree := NewWithIntComparator()
-	rand.Seed(time.Now().UnixNano())
 	for i := 0; i < 100; i++ {
-		key := rand.Int()
+		key := rng.Int()
 		_, err := tree.Insert(key, i)
 		if err != nil {
 			t.Errorf(err.Error())
@@ -141,14 +155,21 @@ func TestRBT_InOrderTraversal(t *testing.T) {
 	if !tree.IsBalanced() && tree.Size() != 100 {
 		t.Errorf("Tree is not balanced")
 	}
-	tree.InOrderTraversal()
+	var out strings.Builder
+	if err := tree.InOrderTraversal(&out); err != nil {
+		t.Fatalf("InOrderTraversal() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != tree.Size()+1 || strings.Count(out.String(), "ROOT\n") != 1 {
+		t.Errorf("InOrderTraversal() wrote %d lines for %d nodes", len(lines), tree.Size())
+	}
 }
 
 func TestRBT_IsBalanced(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	tree := NewWithIntComparator()
-	rand.Seed(time.Now().UnixNano())
 	for i := 0; i < 100; i++ {
-		key := rand.Int()
+		key := rng.Int()
 		_, err := tree.Insert(key, i)
 		if err != nil {
 			t.Errorf(err.Error())
@@ -161,10 +182,10 @@ func TestRBT_IsBalanced(t *testing.T) {
 }
 
 func TestRBT_BlackHeight(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
 	tree := NewWithIntComparator()
-	rand.Seed(time.Now().UnixNano())
 	for i := 0; i < 100; i++ {
-		key := rand.Int()
+		key := rng.Int()
 		_, err := tree.Insert(key, i)
 		if err != nil {
 			t.Errorf(err.Error())
@@ -174,3 +195,280 @@ func TestRBT_BlackHeight(t *testing.T) {
 	height := tree.BlackHeight()
 	fmt.Println("Black height = " + strconv.Itoa(height))
 }
+
+func TestRBT_Conformance(t *testing.T) {
+	treetest.TestOrderedMap(t, func() trees.OrderedMap { return NewWithIntComparator() })
+}
+
+func TestRBT_Navigation(t *testing.T) {
+	tree := NewWithIntComparator()
+	for _, key := range []int{50, 20, 80, 10, 30, 70, 90} {
+		tree.Insert(key, key*10)
+	}
+	tests := []struct {
+		name string
+		fn   func(key interface{}) (interface{}, interface{}, bool)
+		key  int
+		want interface{}
+	}{
+		{"Floor exact", tree.Floor, 30, 30},
+		{"Floor between", tree.Floor, 65, 50},
+		{"Floor below min", tree.Floor, 5, nil},
+		{"Floor above max", tree.Floor, 95, 90},
+		{"Ceiling exact", tree.Ceiling, 30, 30},
+		{"Ceiling between", tree.Ceiling, 31, 50},
+		{"Ceiling below min", tree.Ceiling, 5, 10},
+		{"Ceiling above max", tree.Ceiling, 95, nil},
+		{"Lower exact", tree.Lower, 50, 30},
+		{"Lower between", tree.Lower, 75, 70},
+		{"Lower min", tree.Lower, 10, nil},
+		{"Higher exact", tree.Higher, 50, 70},
+		{"Higher between", tree.Higher, 25, 30},
+		{"Higher max", tree.Higher, 90, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, value, found := tt.fn(tt.key)
+			if found != (tt.want != nil) || key != tt.want {
+				t.Fatalf("got %v, %v, want %v", key, found, tt.want)
+			}
+			if found && value != key.(int)*10 {
+				t.Errorf("value = %v, want %d", value, key.(int)*10)
+			}
+		})
+	}
+
+	if _, _, found := NewWithIntComparator().Floor(1); found {
+		t.Errorf("Floor() on an empty tree found an entry")
+	}
+}
+
+// checkRBT fails the test if the root is red, a red node has a red child, or black heights disagree.
+func checkRBT(t *testing.T, tree *RBT) {
+	t.Helper()
+	if tree.Root().getColor() != BLACK || tree.BlackHeight() < 0 {
+		t.Fatalf("root is red or black heights disagree")
+	}
+	var visit func(node *Node)
+	visit = func(node *Node) {
+		if node == nil {
+			return
+		}
+		if node.getColor() == RED && (node.leftChild().getColor() == RED || node.rightChild().getColor() == RED) {
+			t.Fatalf("red node %v has a red child", node.key())
+		}
+		visit(node.leftChild())
+		visit(node.rightChild())
+	}
+	visit(tree.Root())
+}
+
+func TestRBT_MinMax(t *testing.T) {
+	tree := NewWithIntComparator()
+	if _, _, found := tree.Min(); found {
+		t.Errorf("Min() on an empty tree found an entry")
+	}
+	if _, _, found := tree.PopMax(); found {
+		t.Errorf("PopMax() on an empty tree found an entry")
+	}
+
+	tree = newShuffledTree(500) // keys 0, 2, ..., 998
+	if key, value, _ := tree.Min(); key != 0 || value != 0 {
+		t.Errorf("Min() = %v, %v, want 0, 0", key, value)
+	}
+	if key, value, _ := tree.Max(); key != 998 || value != 9980 {
+		t.Errorf("Max() = %v, %v, want 998, 9980", key, value)
+	}
+
+	lo, hi := 0, 998
+	for !tree.IsEmpty() {
+		key, value, found := tree.PopMin()
+		if !found || key != lo || value != lo*10 {
+			t.Fatalf("PopMin() = %v, %v, %v, want %d", key, value, found, lo)
+		}
+		lo += 2
+		if tree.IsEmpty() {
+			break
+		}
+		if key, _, _ := tree.PopMax(); key != hi {
+			t.Fatalf("PopMax() = %v, want %d", key, hi)
+		}
+		hi -= 2
+		if tree.Size() != (hi-lo)/2+1 {
+			t.Fatalf("Size() = %d, want %d", tree.Size(), (hi-lo)/2+1)
+		}
+		checkRBT(t, tree)
+	}
+	if lo != 500 || hi != 498 {
+		t.Errorf("popped down to %d, %d, want 500, 498", lo, hi)
+	}
+}
+
+// checkSizes fails the test if any node's stored subtree size is wrong.
+func checkSizes(t *testing.T, node *Node) int {
+	t.Helper()
+	if node == nil {
+		return 0
+	}
+	size := 1 + checkSizes(t, node.leftChild()) + checkSizes(t, node.rightChild())
+	if node.subtreeSize() != size {
+		t.Fatalf("node %v stores size %d, want %d", node.key(), node.subtreeSize(), size)
+	}
+
+	return size
+}
+
+func TestRBT_SelectRank(t *testing.T) {
+	tree := NewWithIntComparator()
+	r := rand.New(rand.NewSource(1))
+	present := make(map[int]bool)
+	for step := 0; step < 5000; step++ {
+		key := r.Intn(1000)
+		if r.Intn(3) == 0 {
+			tree.Delete(key)
+			delete(present, key)
+		} else {
+			tree.Insert(key, key)
+			present[key] = true
+		}
+		if step%100 == 0 {
+			checkSizes(t, tree.Root())
+		}
+	}
+	checkSizes(t, tree.Root())
+
+	var sorted []int
+	for key := 0; key < 1000; key++ {
+		if present[key] {
+			sorted = append(sorted, key)
+		}
+	}
+	for i, want := range sorted {
+		key, value, found := tree.Select(i)
+		if !found || key != want || value != want {
+			t.Fatalf("Select(%d) = %v, %v, %v, want %d", i, key, value, found, want)
+		}
+		if rank := tree.Rank(want); rank != i {
+			t.Fatalf("Rank(%d) = %d, want %d", want, rank, i)
+		}
+	}
+	// absent keys rank by the number of smaller keys present
+	for key, rank := -1, 0; key <= 1000; key++ {
+		if got := tree.Rank(key); got != rank {
+			t.Fatalf("Rank(%d) = %d, want %d", key, got, rank)
+		}
+		if present[key] {
+			rank++
+		}
+	}
+	if _, _, found := tree.Select(-1); found {
+		t.Errorf("Select(-1) found an entry")
+	}
+	if _, _, found := tree.Select(len(sorted)); found {
+		t.Errorf("Select(Size()) found an entry")
+	}
+}
+
+func TestRBT_FromSorted(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 3, 7, 8, 100, 1023, 1024, 1025} {
+		keys, values := make([]interface{}, n), make([]interface{}, n)
+		for i := range keys {
+			keys[i], values[i] = i*3, i
+		}
+		tree, err := FromSorted(utils.IntComparator, keys, values)
+		if err != nil {
+			t.Fatalf("FromSorted() of %d keys error = %v", n, err)
+		}
+		if tree.Size() != n {
+			t.Fatalf("Size() = %d, want %d", tree.Size(), n)
+		}
+		checkRBT(t, tree)
+		checkSizes(t, tree.Root())
+		it := tree.Iterator()
+		for i := 0; i < n; i++ {
+			if !it.Next() || it.Key() != i*3 || it.Value() != i {
+				t.Fatalf("entry %d = %v: %v", i, it.Key(), it.Value())
+			}
+		}
+		// the tree stays usable
+		if _, err := tree.Insert(-1, nil); err != nil {
+			t.Errorf("Insert() after FromSorted() error = %v", err)
+		}
+	}
+}
+
+func TestRBT_FromSortedErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		keys   []interface{}
+		values []interface{}
+	}{
+		{"unsorted", []interface{}{1, 3, 2}, nil},
+		{"duplicate", []interface{}{1, 2, 2, 3}, nil},
+		{"descending", []interface{}{3, 2}, nil},
+		{"length mismatch", []interface{}{1, 2}, []interface{}{1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree, err := FromSorted(utils.IntComparator, tt.keys, tt.values)
+			if err == nil || tree != nil {
+				t.Errorf("FromSorted() = %v, %v, want an error", tree, err)
+			}
+		})
+	}
+	if _, err := FromSorted(utils.IntComparator, []interface{}{1, 1}, nil); err == nil {
+		t.Fatal("FromSorted() of duplicate keys returned no error")
+	} else if _, ok := err.(*DuplicateError); !ok {
+		t.Errorf("FromSorted() of duplicate keys error = %T, want *DuplicateError", err)
+	}
+	if _, err := FromSorted(utils.IntComparator, []interface{}{2, 1}, nil); err == nil {
+		t.Fatal("FromSorted() of unsorted keys returned no error")
+	} else if _, ok := err.(*UnsortedError); !ok {
+		t.Errorf("FromSorted() of unsorted keys error = %T, want *UnsortedError", err)
+	}
+}
+
+func TestRBT_NodeAccessors(t *testing.T) {
+	tree := NewWithIntComparator()
+	for _, key := range rand.New(rand.NewSource(1)).Perm(200) {
+		tree.Insert(key, -key)
+	}
+	var keys []interface{}
+	// visit reads the subtree rooted at node through the exported accessors only
+	var visit func(node, parent *Node)
+	visit = func(node, parent *Node) {
+		if node == nil {
+			return
+		}
+		if node.Parent() != parent {
+			t.Fatalf("Parent() of %v = %v, want %v", node.Key(), node.Parent().Key(), parent.Key())
+		}
+		if node.Value() != -node.Key().(int) {
+			t.Fatalf("Value() of %v = %v", node.Key(), node.Value())
+		}
+		if node.IsLeaf() != (node.Left() == nil && node.Right() == nil) {
+			t.Fatalf("IsLeaf() of %v = %v", node.Key(), node.IsLeaf())
+		}
+		if node.Color() == RED && (node.Left().Color() == RED || node.Right().Color() == RED) {
+			t.Fatalf("red node %v has a red child", node.Key())
+		}
+		visit(node.Left(), node)
+		keys = append(keys, node.Key())
+		visit(node.Right(), node)
+	}
+	visit(tree.Root(), nil)
+	if len(keys) != 200 {
+		t.Fatalf("visited %d nodes, want 200", len(keys))
+	}
+	for i, key := range keys {
+		if key != i {
+			t.Fatalf("in-order key %d = %v", i, key)
+		}
+	}
+
+	var nilNode *Node
+	if nilNode.Left() != nil || nilNode.Right() != nil || nilNode.Parent() != nil ||
+		nilNode.Key() != nil || nilNode.Value() != nil || nilNode.IsLeaf() || nilNode.Color() != BLACK {
+		t.Errorf("the accessors of a nil node do not return zero values")
+	}
+}
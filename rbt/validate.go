@@ -0,0 +1,144 @@
+package rbt
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Validate checks every invariant of the tree: key order, parent pointers, that the nodes form a tree,
+// and the tree's size, colors, the black root, red-red violations, black heights, subtree sizes, and aggregates.
+// It follows child pointers only, with explicit stacks, so it terminates on any corruption,
+// and it takes O(n) time.
+// The function returns nil if the tree is valid, or a *ValidationError listing every violation found.
+func (tree *RBT) Validate() error {
+	v := &validator{tree: tree}
+	root := tree.Root()
+	if root != nil && root.getParent() != nil {
+		v.report(root, RuleParent, "the root has a parent")
+	}
+	if root != nil && root.getColor() == RED {
+		v.report(root, RuleRootColor, "the root is red")
+	}
+	nodes := v.preOrder(root)
+	// children come after their parents in pre-order, so a backward pass sees children first
+	infos := make(map[*Node]nodeInfo, len(nodes))
+	for i := len(nodes) - 1; i >= 0; i-- {
+		node := nodes[i]
+		left, right := infos[node.leftChild()], infos[node.rightChild()]
+		info := nodeInfo{count: 1 + left.count + right.count}
+		color := node.getColor()
+		if color != RED && color != BLACK {
+			v.report(node, RuleColor, fmt.Sprintf("color %d", color))
+		}
+		for _, child := range []*Node{node.leftChild(), node.rightChild()} {
+			if color == RED && child != nil && child.getColor() == RED {
+				v.report(node, RuleRedRed, fmt.Sprintf("red child %+v", child.key()))
+			}
+		}
+		if left.blackHeight != right.blackHeight {
+			v.report(node, RuleBlackHeight, fmt.Sprintf("left black height %d, right %d", left.blackHeight, right.blackHeight))
+		}
+		info.blackHeight = left.blackHeight
+		if color != RED {
+			info.blackHeight++
+		}
+		if node.size != info.count {
+			v.report(node, RuleSubtreeSize, fmt.Sprintf("stored size %d, counted %d", node.size, info.count))
+		}
+		if tree.aggregate != nil {
+			want := tree.aggregate.Combine(
+				tree.aggregate.Combine(tree.subtreeAggregate(node.leftChild()), tree.lift(node)),
+				tree.subtreeAggregate(node.rightChild()))
+			if !reflect.DeepEqual(node.agg, want) {
+				v.report(node, RuleAggregate, fmt.Sprintf("stored aggregate %+v, computed %+v", node.agg, want))
+			}
+		}
+		infos[node] = info
+	}
+	if count := infos[root].count; count != tree.Size() {
+		v.report(nil, RuleSize, fmt.Sprintf("size %d, counted %d nodes", tree.Size(), count))
+	}
+	v.checkOrder(root)
+	if len(v.violations) > 0 {
+		return NewValidationError(v.violations)
+	}
+
+	return nil
+}
+
+// validator collects the violations Validate finds.
+type validator struct {
+	tree       *RBT
+	violations []Violation
+}
+
+// nodeInfo holds what Validate computes for a subtree. The zero nodeInfo describes an empty subtree.
+type nodeInfo struct {
+	count       int // the number of nodes
+	blackHeight int // the number of black nodes on the left-most path down to a nil leaf
+}
+
+// report records that node, or the whole tree if node is nil, breaks rule.
+func (v *validator) report(node *Node, rule Rule, detail string) {
+	var key interface{}
+	if node != nil {
+		key = node.key()
+	}
+	v.violations = append(v.violations, Violation{Key: key, Rule: rule, Detail: detail})
+}
+
+// preOrder returns the nodes reachable from root in pre-order, visiting each node once,
+// and reports children whose parent pointers are wrong and nodes reachable more than once.
+func (v *validator) preOrder(root *Node) []*Node {
+	if root == nil {
+		return nil
+	}
+	var nodes []*Node
+	seen := map[*Node]bool{root: true}
+	stack := []*Node{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes = append(nodes, node)
+		// push the right child first so the left one is visited first
+		for _, child := range []*Node{node.rightChild(), node.leftChild()} {
+			switch {
+			case child == nil:
+				continue
+			case seen[child]:
+				v.report(child, RuleStructure, fmt.Sprintf("reachable again from %+v", node.key()))
+				continue
+			case child.getParent() != node:
+				v.report(child, RuleParent, fmt.Sprintf("child of %+v points at another parent", node.key()))
+			}
+			seen[child] = true
+			stack = append(stack, child)
+		}
+	}
+
+	return nodes
+}
+
+// checkOrder walks the tree in order and reports every key that is not greater than the key before it.
+func (v *validator) checkOrder(root *Node) {
+	var prev *Node
+	var stack []*Node
+	seen := make(map[*Node]bool)
+	for node := root; node != nil || len(stack) > 0; {
+		for node != nil && !seen[node] {
+			seen[node] = true
+			stack = append(stack, node)
+			node = node.leftChild()
+		}
+		if len(stack) == 0 {
+			return
+		}
+		node = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if prev != nil && v.tree.comparator(prev.key(), node.key()) >= 0 {
+			v.report(node, RuleOrder, fmt.Sprintf("not greater than the key before it, %+v", prev.key()))
+		}
+		prev = node
+		node = node.rightChild()
+	}
+}
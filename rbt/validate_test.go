@@ -0,0 +1,145 @@
+package rbt
+
+import (
+	"github.com/emirpasic/gods/utils"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// hasViolation reports whether err is a *ValidationError listing a violation of rule by key.
+func hasViolation(err error, key interface{}, rule Rule) bool {
+	validationErr, ok := err.(*ValidationError)
+	if !ok {
+		return false
+	}
+	for _, v := range validationErr.Violations {
+		if v.Key == key && v.Rule == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newFifteen returns the perfectly balanced tree of the keys 0 to 14.
+func newFifteen(t *testing.T) *RBT {
+	keys := make([]interface{}, 15)
+	for i := range keys {
+		keys[i] = i
+	}
+	tree, err := FromSorted(utils.IntComparator, keys, nil)
+	if err != nil {
+		t.Fatalf("FromSorted() error = %v", err)
+	}
+
+	return tree
+}
+
+func TestRBT_Validate(t *testing.T) {
+	tree := NewWithIntComparator()
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("Validate() of an empty tree error = %v", err)
+	}
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 3000; i++ {
+		key := rng.Intn(500)
+		if tree.Search(key) {
+			tree.Delete(key)
+		} else {
+			tree.Insert(key, i)
+		}
+		if i%100 == 0 {
+			if err := tree.Validate(); err != nil {
+				t.Fatalf("Validate() after %d operations error = %v", i, err)
+			}
+		}
+	}
+}
+
+func TestRBT_ValidateViolations(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(tree *RBT) *Node // breaks the tree and returns the node to blame, or nil
+		rule    Rule
+	}{
+		{"order", func(tree *RBT) *Node {
+			node := tree.Root().leftChild()
+			node.setKey(1000)
+			// the walk meets 1000 first, so the key after it is out of order
+			return node.successor()
+		}, RuleOrder},
+		{"parent", func(tree *RBT) *Node {
+			node := tree.Root().rightChild().leftChild()
+			node.setParent(tree.Root())
+			return node
+		}, RuleParent},
+		{"structure", func(tree *RBT) *Node {
+			leaf := tree.minNode()
+			leaf.setLeftChild(tree.Root())
+			return tree.Root()
+		}, RuleStructure},
+		{"size", func(tree *RBT) *Node {
+			tree.setSize(tree.Size() + 1)
+			return nil
+		}, RuleSize},
+		{"color", func(tree *RBT) *Node {
+			node := tree.minNode()
+			node.setColor(7)
+			return node
+		}, RuleColor},
+		{"root color", func(tree *RBT) *Node {
+			tree.Root().setColor(RED)
+			return tree.Root()
+		}, RuleRootColor},
+		{"red red", func(tree *RBT) *Node {
+			// the deepest level is red, so a red parent of it breaks the rule
+			node := tree.Root().leftChild().leftChild()
+			node.setColor(RED)
+			return node
+		}, RuleRedRed},
+		{"black height", func(tree *RBT) *Node {
+			tree.Root().leftChild().setColor(RED)
+			return tree.Root()
+		}, RuleBlackHeight},
+		{"subtree size", func(tree *RBT) *Node {
+			node := tree.Root().rightChild()
+			node.size++
+			return node
+		}, RuleSubtreeSize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newFifteen(t)
+			var key interface{}
+			if node := tt.corrupt(tree); node != nil {
+				key = node.key()
+			}
+			err := tree.Validate()
+			if !hasViolation(err, key, tt.rule) {
+				t.Fatalf("Validate() = %v, want a %s violation at %v", err, tt.rule, key)
+			}
+			if !strings.Contains(err.Error(), string(tt.rule)) {
+				t.Errorf("Error() = %q does not name %s", err.Error(), tt.rule)
+			}
+		})
+	}
+}
+
+func TestRBT_ValidateAggregate(t *testing.T) {
+	keys := make([]interface{}, 15)
+	for i := range keys {
+		keys[i] = i
+	}
+	tree := NewWithAggregate(utils.IntComparator, sumOfValues)
+	if err := tree.loadSorted(keys, keys); err != nil {
+		t.Fatalf("loadSorted() error = %v", err)
+	}
+	if err := tree.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	tree.Root().leftChild().agg = -1
+	if err := tree.Validate(); !hasViolation(err, tree.Root().leftChild().key(), RuleAggregate) {
+		t.Errorf("Validate() = %v, want an aggregate violation", err)
+	}
+}
@@ -0,0 +1,22 @@
+package trees
+
+// Layout selects how the Render methods of bst.BST, avl.AVL, and rbt.RBT draw a tree.
+type Layout int
+
+const (
+	Sideways Layout = iota // one node per line, root at the left, right subtree above; the zero value
+	TopDown                // root at the top, children centered below their parent
+)
+
+// RenderOptions configures the Render methods of bst.BST, avl.AVL, and rbt.RBT.
+// The zero RenderOptions draws the keys sideways without annotations.
+type RenderOptions struct {
+	// Layout chooses between the sideways and the top-down drawing.
+	Layout Layout
+	// Annotate marks every rbt.RBT node with its color, (R) or (B),
+	// and every avl.AVL node with its balance factor, such as (+1). It has no effect on a bst.BST.
+	Annotate bool
+	// Label takes a node's key and value and returns the text drawn for the node.
+	// If nil, the key is converted to a string.
+	Label func(key, value interface{}) string
+}
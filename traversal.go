@@ -0,0 +1,30 @@
+package trees
+
+// TraversalOrder selects the order in which the Walk methods of bst.BST, avl.AVL, and rbt.RBT visit nodes.
+type TraversalOrder int
+
+const (
+	InOrder        TraversalOrder = iota // left subtree, node, right subtree: ascending key order
+	PreOrder                             // node, left subtree, right subtree
+	PostOrder                            // left subtree, right subtree, node
+	LevelOrder                           // breadth first, top to bottom and left to right
+	ReverseInOrder                       // right subtree, node, left subtree: descending key order
+)
+
+// String returns the name of the traversal order.
+func (order TraversalOrder) String() string {
+	switch order {
+	case InOrder:
+		return "InOrder"
+	case PreOrder:
+		return "PreOrder"
+	case PostOrder:
+		return "PostOrder"
+	case LevelOrder:
+		return "LevelOrder"
+	case ReverseInOrder:
+		return "ReverseInOrder"
+	default:
+		return "TraversalOrder(invalid)"
+	}
+}
@@ -0,0 +1,32 @@
+package trees
+
+// OrderedReader is the read-only half of an ordered map.
+// bst.BST, avl.AVL, and rbt.RBT all satisfy it,
+// so callers that only look entries up can accept any of the three.
+type OrderedReader interface {
+	// Search takes a key and returns a boolean stating whether the key was found or not.
+	Search(key interface{}) bool
+	// ReturnNodeValue takes a key and returns the value associated with the key or an error, if there was one.
+	ReturnNodeValue(key interface{}) (interface{}, error)
+	// Size returns the number of entries in the map.
+	Size() int
+	// IsEmpty returns a boolean stating whether the map is empty or not.
+	IsEmpty() bool
+}
+
+// OrderedMap is the full read-write surface shared by bst.BST, avl.AVL, and rbt.RBT.
+// Keys are ordered by the comparator the map was constructed with, and duplicates are not allowed.
+type OrderedMap interface {
+	OrderedReader
+	// Insert takes a key and a value and stores them as a new entry.
+	// It returns the inserted key or an error if the key already exists.
+	Insert(key, value interface{}) (interface{}, error)
+	// Update takes a key and a value and replaces the value of an existing entry.
+	// It returns the new value or an error if the key does not exist.
+	Update(key, value interface{}) (interface{}, error)
+	// Delete takes a key and removes its entry.
+	// It returns the deleted key or an error if the key does not exist.
+	Delete(key interface{}) (interface{}, error)
+	// Clear removes every entry.
+	Clear()
+}
@@ -0,0 +1,310 @@
+package treetest
+
+import (
+	"fmt"
+	"github.com/chancetudor/trees"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// OpKind is the kind of operation an Op performs.
+type OpKind int
+
+const (
+	OpInsert OpKind = iota // Insert(Key, Value)
+	OpUpdate               // Update(Key, Value)
+	OpDelete               // Delete(Key)
+	OpSearch               // Search(Key) and ReturnNodeValue(Key)
+	OpClear                // Clear()
+)
+
+// Op is one operation of a model-based test run.
+type Op struct {
+	Kind  OpKind
+	Key   int
+	Value int
+}
+
+// String returns the operation as a call, such as Insert(3, 7).
+func (op Op) String() string {
+	switch op.Kind {
+	case OpInsert:
+		return fmt.Sprintf("Insert(%d, %d)", op.Key, op.Value)
+	case OpUpdate:
+		return fmt.Sprintf("Update(%d, %d)", op.Key, op.Value)
+	case OpDelete:
+		return fmt.Sprintf("Delete(%d)", op.Key)
+	case OpSearch:
+		return fmt.Sprintf("Search(%d)", op.Key)
+	case OpClear:
+		return "Clear()"
+	default:
+		return fmt.Sprintf("Op(%d)", op.Kind)
+	}
+}
+
+// Checker takes a map after an operation and returns an error if the map breaks one of its own invariants,
+// for example by returning the result of a tree's Validate method.
+type Checker func(m trees.OrderedMap) error
+
+// Failure describes the first operation after which a map disagreed with the model or failed its Checker.
+type Failure struct {
+	Step    int    // the index of the operation
+	Op      Op     // the operation
+	Message string // what went wrong
+}
+
+func (f *Failure) Error() string {
+	return fmt.Sprintf("step %d: %v: %s", f.Step, f.Op, f.Message)
+}
+
+// opWeights maps a random number below 100 to an operation kind, making inserts the most common
+// and Clear rare enough that the map usually grows.
+var opWeights = [...]struct {
+	below int
+	kind  OpKind
+}{
+	{40, OpInsert},
+	{65, OpDelete},
+	{80, OpUpdate},
+	{99, OpSearch},
+	{100, OpClear},
+}
+
+// RandomOps returns n operations on keys in [0, keys), generated from seed,
+// so the same arguments always return the same operations.
+func RandomOps(seed int64, n, keys int) []Op {
+	r := rand.New(rand.NewSource(seed))
+	ops := make([]Op, n)
+	for i := range ops {
+		roll := r.Intn(100)
+		for _, w := range opWeights {
+			if roll < w.below {
+				ops[i].Kind = w.kind
+				break
+			}
+		}
+		ops[i].Key = r.Intn(keys)
+		ops[i].Value = r.Intn(256)
+	}
+
+	return ops
+}
+
+// opBytes is the number of bytes DecodeOps reads for each operation.
+const opBytes = 3
+
+// DecodeOps turns arbitrary bytes, such as a fuzzer's input, into operations.
+// Every three bytes make one operation: a kind, a key between -128 and 127, and a value between 0 and 255.
+// Leftover bytes are ignored.
+func DecodeOps(data []byte) []Op {
+	ops := make([]Op, 0, len(data)/opBytes)
+	for ; len(data) >= opBytes; data = data[opBytes:] {
+		var kind OpKind
+		switch b := data[0] % 16; {
+		case b < 6:
+			kind = OpInsert
+		case b < 9:
+			kind = OpDelete
+		case b < 12:
+			kind = OpUpdate
+		case b < 15:
+			kind = OpSearch
+		default:
+			kind = OpClear
+		}
+		ops = append(ops, Op{Kind: kind, Key: int(int8(data[1])), Value: int(data[2])})
+	}
+
+	return ops
+}
+
+// EncodeOps is the inverse of DecodeOps for operations whose keys and values fit its ranges.
+// It is useful for seeding a fuzzer's corpus.
+func EncodeOps(ops []Op) []byte {
+	kinds := map[OpKind]byte{OpInsert: 0, OpDelete: 6, OpUpdate: 9, OpSearch: 12, OpClear: 15}
+	data := make([]byte, 0, len(ops)*opBytes)
+	for _, op := range ops {
+		data = append(data, kinds[op.Kind], byte(int8(op.Key)), byte(op.Value))
+	}
+
+	return data
+}
+
+// Run applies ops to m and to a plain Go map used as the reference model.
+// After every operation it compares the results, Size, and IsEmpty with the model's, then calls check, if not nil.
+// At the end it looks up every key of the model in m.
+// The function returns nil if m agreed with the model throughout, or a *Failure describing the first disagreement.
+// A panic in m is reported as a Failure too.
+func Run(m trees.OrderedMap, ops []Op, check Checker) (err error) {
+	model := make(map[int]int)
+	step := 0
+	defer func() {
+		if r := recover(); r != nil {
+			if step == len(ops) {
+				step--
+			}
+			err = &Failure{Step: step, Op: ops[step], Message: fmt.Sprintf("panic: %v", r)}
+		}
+	}()
+	for ; step < len(ops); step++ {
+		op := ops[step]
+		if message := apply(m, model, op); message != "" {
+			return &Failure{Step: step, Op: op, Message: message}
+		}
+		if m.Size() != len(model) || m.IsEmpty() != (len(model) == 0) {
+			return &Failure{Step: step, Op: op,
+				Message: fmt.Sprintf("Size() = %d, IsEmpty() = %v, want %d", m.Size(), m.IsEmpty(), len(model))}
+		}
+		if check != nil {
+			if err := check(m); err != nil {
+				return &Failure{Step: step, Op: op, Message: err.Error()}
+			}
+		}
+	}
+	for key, value := range model {
+		step = len(ops)
+		if got, err := m.ReturnNodeValue(key); err != nil || got != value {
+			return &Failure{Step: len(ops) - 1, Op: ops[len(ops)-1],
+				Message: fmt.Sprintf("at the end, ReturnNodeValue(%d) = %v, %v, want %d", key, got, err, value)}
+		}
+	}
+
+	return nil
+}
+
+// apply performs op on m and on model and returns a description of any disagreement, or "".
+func apply(m trees.OrderedMap, model map[int]int, op Op) string {
+	want, exists := model[op.Key]
+	switch op.Kind {
+	case OpInsert:
+		got, err := m.Insert(op.Key, op.Value)
+		switch {
+		case exists && err == nil:
+			return "inserted a duplicate key"
+		case !exists && (err != nil || got != op.Key):
+			return fmt.Sprintf("got %v, %v, want %d", got, err, op.Key)
+		case !exists:
+			model[op.Key] = op.Value
+		}
+	case OpUpdate:
+		got, err := m.Update(op.Key, op.Value)
+		switch {
+		case !exists && err == nil:
+			return "updated a missing key"
+		case exists && (err != nil || got != op.Value):
+			return fmt.Sprintf("got %v, %v, want %d", got, err, op.Value)
+		case exists:
+			model[op.Key] = op.Value
+		}
+	case OpDelete:
+		got, err := m.Delete(op.Key)
+		switch {
+		case !exists && err == nil:
+			return "deleted a missing key"
+		case exists && (err != nil || got != op.Key):
+			return fmt.Sprintf("got %v, %v, want %d", got, err, op.Key)
+		}
+		delete(model, op.Key)
+	case OpSearch:
+		if found := m.Search(op.Key); found != exists {
+			return fmt.Sprintf("Search() = %v, want %v", found, exists)
+		}
+		got, err := m.ReturnNodeValue(op.Key)
+		switch {
+		case !exists && err == nil:
+			return fmt.Sprintf("ReturnNodeValue() of a missing key = %v", got)
+		case exists && (err != nil || got != want):
+			return fmt.Sprintf("ReturnNodeValue() = %v, %v, want %d", got, err, want)
+		}
+	case OpClear:
+		m.Clear()
+		for key := range model {
+			delete(model, key)
+		}
+	}
+
+	return ""
+}
+
+// Shrink takes operations that make Run fail on maps from newMap
+// and returns a shorter sequence that still fails, found by repeatedly removing runs of operations
+// (delta debugging). No single operation can be removed from the result without Run passing.
+func Shrink(newMap Factory, ops []Op, check Checker) []Op {
+	fails := func(candidate []Op) bool {
+		return len(candidate) > 0 && Run(newMap(), candidate, check) != nil
+	}
+	if !fails(ops) {
+		return ops
+	}
+	ops = append([]Op(nil), ops...)
+	for chunk := len(ops) / 2; chunk > 0; {
+		removed := false
+		for start := 0; start+chunk <= len(ops); {
+			candidate := append(append([]Op(nil), ops[:start]...), ops[start+chunk:]...)
+			if fails(candidate) {
+				ops = candidate
+				removed = true
+			} else {
+				start += chunk
+			}
+		}
+		if !removed {
+			chunk /= 2
+		}
+	}
+
+	return ops
+}
+
+// formatOps returns the operations one per line, for failure messages.
+func formatOps(ops []Op) string {
+	var b strings.Builder
+	for i, op := range ops {
+		fmt.Fprintf(&b, "\t%d: %v\n", i, op)
+	}
+
+	return b.String()
+}
+
+// TestModel runs random operation sequences from fixed seeds against maps from newMap,
+// comparing every result with a plain Go map and calling check after every operation.
+// The seeds run in parallel subtests. A failing sequence is shrunk to a minimal one before it is reported,
+// together with its seed, so the failure can be replayed with RandomOps and Run.
+func TestModel(t *testing.T, newMap Factory, check Checker) {
+	seeds, n := int64(16), 2000
+	if testing.Short() {
+		seeds, n = 4, 500
+	}
+	for seed := int64(1); seed <= seeds; seed++ {
+		seed := seed
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) {
+			t.Parallel()
+			// a small key space makes duplicates, updates, and deletes of present keys common
+			ops := RandomOps(seed, n, 64)
+			if err := Run(newMap(), ops, check); err != nil {
+				minimal := Shrink(newMap, ops, check)
+				t.Fatalf("RandomOps(%d, %d, 64): %v\nminimal failing sequence:\n%s\n%v",
+					seed, n, err, formatOps(minimal), Run(newMap(), minimal, check))
+			}
+		})
+	}
+}
+
+// Fuzz runs a fuzz target that decodes the fuzzer's input with DecodeOps and runs it against maps from newMap,
+// as TestModel does. Call it from a FuzzXxx function and run it with go test -fuzz.
+// Without -fuzz, it runs only the seed corpus.
+func Fuzz(f *testing.F, newMap Factory, check Checker) {
+	f.Add([]byte{})
+	for seed := int64(1); seed <= 4; seed++ {
+		f.Add(EncodeOps(RandomOps(seed, 100, 16)))
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ops := DecodeOps(data)
+		if err := Run(newMap(), ops, check); err != nil {
+			minimal := Shrink(newMap, ops, check)
+			t.Fatalf("%v\nminimal failing sequence:\n%s\n%v", err, formatOps(minimal), Run(newMap(), minimal, check))
+		}
+	})
+}
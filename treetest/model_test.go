@@ -0,0 +1,124 @@
+package treetest
+
+import (
+	"errors"
+	"github.com/chancetudor/trees"
+	"reflect"
+	"testing"
+)
+
+// goMap is an OrderedMap backed by a Go map, with an optional bug:
+// deleting the key forget leaves the entry in place.
+type goMap struct {
+	entries map[interface{}]interface{}
+	forget  interface{}
+}
+
+func newGoMap(forget interface{}) Factory {
+	return func() trees.OrderedMap {
+		return &goMap{entries: make(map[interface{}]interface{}), forget: forget}
+	}
+}
+
+var errMissing = errors.New("missing key")
+
+func (m *goMap) Search(key interface{}) bool {
+	_, ok := m.entries[key]
+	return ok
+}
+
+func (m *goMap) ReturnNodeValue(key interface{}) (interface{}, error) {
+	value, ok := m.entries[key]
+	if !ok {
+		return nil, errMissing
+	}
+	return value, nil
+}
+
+func (m *goMap) Size() int {
+	return len(m.entries)
+}
+
+func (m *goMap) IsEmpty() bool {
+	return len(m.entries) == 0
+}
+
+func (m *goMap) Insert(key, value interface{}) (interface{}, error) {
+	if m.Search(key) {
+		return nil, errors.New("duplicate key")
+	}
+	m.entries[key] = value
+	return key, nil
+}
+
+func (m *goMap) Update(key, value interface{}) (interface{}, error) {
+	if !m.Search(key) {
+		return nil, errMissing
+	}
+	m.entries[key] = value
+	return value, nil
+}
+
+func (m *goMap) Delete(key interface{}) (interface{}, error) {
+	if !m.Search(key) {
+		return nil, errMissing
+	}
+	if key != m.forget {
+		delete(m.entries, key)
+	}
+	return key, nil
+}
+
+func (m *goMap) Clear() {
+	m.entries = make(map[interface{}]interface{})
+}
+
+func TestModel_CorrectMap(t *testing.T) {
+	TestModel(t, newGoMap(nil), nil)
+	TestOrderedMap(t, newGoMap(nil))
+}
+
+func TestRun_FindsAndShrinksBug(t *testing.T) {
+	ops := RandomOps(1, 2000, 64)
+	err := Run(newGoMap(13)(), ops, nil)
+	var failure *Failure
+	if !errors.As(err, &failure) {
+		t.Fatalf("Run() of a buggy map = %v, want a *Failure", err)
+	}
+	minimal := Shrink(newGoMap(13), ops, nil)
+	if len(minimal) != 2 || minimal[0].Kind != OpInsert || minimal[1].Kind != OpDelete ||
+		minimal[0].Key != 13 || minimal[1].Key != 13 {
+		t.Errorf("Shrink() = %v, want Insert(13) then Delete(13)", minimal)
+	}
+}
+
+func TestRun_Checker(t *testing.T) {
+	broken := errors.New("broken invariant")
+	check := func(m trees.OrderedMap) error {
+		if m.Size() > 3 {
+			return broken
+		}
+		return nil
+	}
+	ops := []Op{{OpInsert, 1, 0}, {OpInsert, 2, 0}, {OpSearch, 2, 0}, {OpInsert, 3, 0}, {OpInsert, 4, 0}}
+	err := Run(newGoMap(nil)(), ops, check)
+	if failure, ok := err.(*Failure); !ok || failure.Step != 4 {
+		t.Errorf("Run() = %v, want a failure at step 4", err)
+	}
+	if got := Shrink(newGoMap(nil), ops, check); len(got) != 4 {
+		t.Errorf("Shrink() = %v, want the four inserts", got)
+	}
+}
+
+func TestDecodeOps(t *testing.T) {
+	ops := RandomOps(7, 300, 100)
+	if !reflect.DeepEqual(RandomOps(7, 300, 100), ops) {
+		t.Fatalf("RandomOps() is not deterministic")
+	}
+	if got := DecodeOps(EncodeOps(ops)); !reflect.DeepEqual(got, ops) {
+		t.Errorf("DecodeOps(EncodeOps()) changed the operations")
+	}
+	if got := DecodeOps([]byte{255, 255, 7, 1}); !reflect.DeepEqual(got, []Op{{OpClear, -1, 7}}) {
+		t.Errorf("DecodeOps() = %v", got)
+	}
+}
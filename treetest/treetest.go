@@ -0,0 +1,235 @@
+// Package treetest implements a conformance suite for implementations of trees.OrderedMap.
+// Each tree package runs the suite from its own tests, and so can any other implementation:
+//
+//	func TestConformance(t *testing.T) {
+//		treetest.TestOrderedMap(t, func() trees.OrderedMap { return NewWithIntComparator() })
+//	}
+package treetest
+
+import (
+	"github.com/chancetudor/trees"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// Factory returns a new, empty OrderedMap whose keys are ints ordered ascending.
+type Factory func() trees.OrderedMap
+
+// TestOrderedMap runs the conformance suite against maps returned by newMap.
+// Every subtest starts from a fresh map and uses a fixed seed, so failures are reproducible.
+func TestOrderedMap(t *testing.T, newMap Factory) {
+	t.Run("Empty", func(t *testing.T) { testEmpty(t, newMap()) })
+	t.Run("InsertSearch", func(t *testing.T) { testInsertSearch(t, newMap()) })
+	t.Run("Duplicate", func(t *testing.T) { testDuplicate(t, newMap()) })
+	t.Run("Update", func(t *testing.T) { testUpdate(t, newMap()) })
+	t.Run("Missing", func(t *testing.T) { testMissing(t, newMap()) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newMap()) })
+	t.Run("Clear", func(t *testing.T) { testClear(t, newMap()) })
+	t.Run("Random", func(t *testing.T) { testRandom(t, newMap()) })
+}
+
+func testEmpty(t *testing.T, m trees.OrderedMap) {
+	if !m.IsEmpty() || m.Size() != 0 {
+		t.Fatalf("new map: IsEmpty() = %v, Size() = %d, want true, 0", m.IsEmpty(), m.Size())
+	}
+	if m.Search(1) {
+		t.Errorf("Search(1) on empty map = true")
+	}
+	if _, err := m.ReturnNodeValue(1); err == nil {
+		t.Errorf("ReturnNodeValue(1) on empty map returned no error")
+	}
+	if _, err := m.Delete(1); err == nil {
+		t.Errorf("Delete(1) on empty map returned no error")
+	}
+}
+
+func testInsertSearch(t *testing.T, m trees.OrderedMap) {
+	r := rand.New(rand.NewSource(1))
+	keys := r.Perm(500)
+	for i, k := range keys {
+		got, err := m.Insert(k, i)
+		if err != nil {
+			t.Fatalf("Insert(%d) error = %v", k, err)
+		}
+		if got != k {
+			t.Fatalf("Insert(%d) got = %v", k, got)
+		}
+		if m.Size() != i+1 {
+			t.Fatalf("Size() after %d inserts = %d", i+1, m.Size())
+		}
+	}
+	for i, k := range keys {
+		if !m.Search(k) {
+			t.Errorf("Search(%d) = false", k)
+		}
+		v, err := m.ReturnNodeValue(k)
+		if err != nil || v != i {
+			t.Errorf("ReturnNodeValue(%d) = %v, %v, want %d", k, v, err, i)
+		}
+	}
+	if m.IsEmpty() {
+		t.Errorf("IsEmpty() after inserts = true")
+	}
+}
+
+func testDuplicate(t *testing.T, m trees.OrderedMap) {
+	for _, k := range []int{5, 3, 8} {
+		if _, err := m.Insert(k, k); err != nil {
+			t.Fatalf("Insert(%d) error = %v", k, err)
+		}
+	}
+	for _, k := range []int{5, 3, 8} {
+		if _, err := m.Insert(k, -1); err == nil {
+			t.Errorf("Insert(%d) of duplicate returned no error", k)
+		}
+		if v, _ := m.ReturnNodeValue(k); v != k {
+			t.Errorf("duplicate Insert(%d) changed value to %v", k, v)
+		}
+	}
+	if m.Size() != 3 {
+		t.Errorf("Size() after duplicates = %d, want 3", m.Size())
+	}
+}
+
+func testUpdate(t *testing.T, m trees.OrderedMap) {
+	for k := 0; k < 100; k++ {
+		m.Insert(k, k)
+	}
+	for k := 0; k < 100; k++ {
+		got, err := m.Update(k, k*10)
+		if err != nil || got != k*10 {
+			t.Errorf("Update(%d) = %v, %v, want %d", k, got, err, k*10)
+		}
+	}
+	for k := 0; k < 100; k++ {
+		if v, _ := m.ReturnNodeValue(k); v != k*10 {
+			t.Errorf("ReturnNodeValue(%d) after Update = %v, want %d", k, v, k*10)
+		}
+	}
+	if m.Size() != 100 {
+		t.Errorf("Size() after updates = %d, want 100", m.Size())
+	}
+}
+
+func testMissing(t *testing.T, m trees.OrderedMap) {
+	for k := 0; k < 100; k += 2 {
+		m.Insert(k, k)
+	}
+	for k := 1; k < 100; k += 2 {
+		if m.Search(k) {
+			t.Errorf("Search(%d) of missing key = true", k)
+		}
+		if _, err := m.ReturnNodeValue(k); err == nil {
+			t.Errorf("ReturnNodeValue(%d) of missing key returned no error", k)
+		}
+		if _, err := m.Update(k, k); err == nil {
+			t.Errorf("Update(%d) of missing key returned no error", k)
+		}
+		if _, err := m.Delete(k); err == nil {
+			t.Errorf("Delete(%d) of missing key returned no error", k)
+		}
+	}
+	if m.Size() != 50 {
+		t.Errorf("Size() = %d, want 50", m.Size())
+	}
+}
+
+func testDelete(t *testing.T, m trees.OrderedMap) {
+	r := rand.New(rand.NewSource(2))
+	keys := r.Perm(500)
+	for _, k := range keys {
+		m.Insert(k, k)
+	}
+	r.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	for i, k := range keys {
+		got, err := m.Delete(k)
+		if err != nil || got != k {
+			t.Fatalf("Delete(%d) = %v, %v", k, got, err)
+		}
+		if m.Search(k) {
+			t.Fatalf("Search(%d) after Delete = true", k)
+		}
+		if m.Size() != len(keys)-i-1 {
+			t.Fatalf("Size() after %d deletes = %d", i+1, m.Size())
+		}
+	}
+	if !m.IsEmpty() {
+		t.Errorf("IsEmpty() after deleting every key = false")
+	}
+	// the map must be reusable once drained
+	if _, err := m.Insert(7, 7); err != nil || !m.Search(7) {
+		t.Errorf("Insert after draining failed: %v", err)
+	}
+}
+
+func testClear(t *testing.T, m trees.OrderedMap) {
+	for k := 0; k < 100; k++ {
+		m.Insert(k, k)
+	}
+	m.Clear()
+	if !m.IsEmpty() || m.Size() != 0 {
+		t.Fatalf("after Clear: IsEmpty() = %v, Size() = %d", m.IsEmpty(), m.Size())
+	}
+	if m.Search(50) {
+		t.Errorf("Search(50) after Clear = true")
+	}
+	if _, err := m.Insert(50, 1); err != nil {
+		t.Errorf("Insert after Clear error = %v", err)
+	}
+}
+
+// testRandom mixes every operation and compares each result against a Go map.
+func testRandom(t *testing.T, m trees.OrderedMap) {
+	r := rand.New(rand.NewSource(3))
+	model := make(map[int]int)
+	for step := 0; step < 20000; step++ {
+		k := r.Intn(300)
+		_, exists := model[k]
+		switch op := r.Intn(4); op {
+		case 0:
+			_, err := m.Insert(k, step)
+			if (err == nil) == exists {
+				t.Fatalf("step %d: Insert(%d) error = %v, key present = %v", step, k, err, exists)
+			}
+			if !exists {
+				model[k] = step
+			}
+		case 1:
+			_, err := m.Delete(k)
+			if (err == nil) != exists {
+				t.Fatalf("step %d: Delete(%d) error = %v, key present = %v", step, k, err, exists)
+			}
+			delete(model, k)
+		case 2:
+			_, err := m.Update(k, step)
+			if (err == nil) != exists {
+				t.Fatalf("step %d: Update(%d) error = %v, key present = %v", step, k, err, exists)
+			}
+			if exists {
+				model[k] = step
+			}
+		case 3:
+			v, err := m.ReturnNodeValue(k)
+			if exists && (err != nil || v != model[k]) {
+				t.Fatalf("step %d: ReturnNodeValue(%d) = %v, %v, want %d", step, k, v, err, model[k])
+			}
+			if !exists && err == nil {
+				t.Fatalf("step %d: ReturnNodeValue(%d) of missing key returned no error", step, k)
+			}
+		}
+		if m.Size() != len(model) {
+			t.Fatalf("step %d: Size() = %d, want %d", step, m.Size(), len(model))
+		}
+	}
+	keys := make([]int, 0, len(model))
+	for k := range model {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	for _, k := range keys {
+		if !m.Search(k) {
+			t.Errorf("Search(%d) = false at end of run", k)
+		}
+	}
+}